@@ -1,6 +1,6 @@
 module github.com/petar/GoLLRB
 
-go 1.15
+go 1.18
 
 require (
 )
@@ -0,0 +1,102 @@
+package llrb
+
+// AugmentFunc computes the summary of a subtree from the item at its root and the
+// summaries of its left and right subtrees, which are nil when those are empty.
+//
+// A summary must depend only on the ascending sequence of items it covers, and not
+// on the shape of the subtree; sums, minima, maxima and bounding boxes all qualify.
+// Rotations rely on this, and so does Aggregate, which calls the function with the
+// summaries of arbitrary runs of adjacent items rather than of whole subtrees.
+type AugmentFunc func(item Item, left, right interface{}) interface{}
+
+// SetAugment makes t maintain a summary of every subtree, computed by augment,
+// from now on. The summaries of the nodes already in the tree are computed at once.
+// A nil augment stops the maintenance of summaries.
+func (t *LLRB) SetAugment(augment AugmentFunc) {
+	t.augment = augment
+	t.mods++
+	if augment != nil {
+		t.root = t.updateAll(t.root)
+		return
+	}
+	t.root = t.clearSummaries(t.root)
+}
+
+func (t *LLRB) clearSummaries(h *Node) *Node {
+	if h == nil {
+		return nil
+	}
+	h = t.mutable(h)
+	if !t.ranked {
+		h.aug = nil
+	} else if h.aug != nil {
+		h.aug.summary = nil
+	}
+	h.Left = t.clearSummaries(h.Left)
+	h.Right = t.clearSummaries(h.Right)
+	return h
+}
+
+func summary(h *Node) interface{} {
+	if h == nil || h.aug == nil {
+		return nil
+	}
+	return h.aug.summary
+}
+
+// Summary returns the summary of all elements in the tree, or nil if it is empty.
+func (t *LLRB) Summary() interface{} {
+	t.mustBeAugmented()
+	return summary(t.root)
+}
+
+func (t *LLRB) mustBeAugmented() {
+	if t.augment == nil {
+		panic("augmentation not enabled")
+	}
+}
+
+// Aggregate returns the summary of the elements greater or equal to greaterOrEqual
+// and less than lessThan, or nil if there are none. It combines O(log n) subtree
+// summaries instead of visiting every element in the range.
+func (t *LLRB) Aggregate(greaterOrEqual, lessThan Item) interface{} {
+	t.mustBeAugmented()
+	h := t.root
+	for h != nil {
+		switch {
+		case t.less(h.Item, greaterOrEqual):
+			h = h.Right
+		case !t.less(h.Item, lessThan):
+			h = h.Left
+		default:
+			// @h is the highest node within the range; the range covers
+			// a suffix of its left subtree and a prefix of its right subtree
+			return t.augment(h.Item, t.aggregateFrom(h.Left, greaterOrEqual), t.aggregateTo(h.Right, lessThan))
+		}
+	}
+	return nil
+}
+
+// aggregateFrom returns the summary of the elements in the subtree at h
+// that are greater or equal to inf.
+func (t *LLRB) aggregateFrom(h *Node, inf Item) interface{} {
+	for h != nil && t.less(h.Item, inf) {
+		h = h.Right
+	}
+	if h == nil {
+		return nil
+	}
+	return t.augment(h.Item, t.aggregateFrom(h.Left, inf), summary(h.Right))
+}
+
+// aggregateTo returns the summary of the elements in the subtree at h
+// that are less than sup.
+func (t *LLRB) aggregateTo(h *Node, sup Item) interface{} {
+	for h != nil && !t.less(h.Item, sup) {
+		h = h.Left
+	}
+	if h == nil {
+		return nil
+	}
+	return t.augment(h.Item, summary(h.Left), t.aggregateTo(h.Right, sup))
+}
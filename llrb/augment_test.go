@@ -0,0 +1,126 @@
+package llrb
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// sumAugment summarizes a subtree by the sum of its Int items
+func sumAugment(item Item, left, right interface{}) interface{} {
+	s := int(item.(Int))
+	if left != nil {
+		s += left.(int)
+	}
+	if right != nil {
+		s += right.(int)
+	}
+	return s
+}
+
+func bruteSum(tree *LLRB, lo, hi Item) interface{} {
+	var s interface{}
+	tree.AscendRange(lo, hi, func(i Item) bool {
+		if s == nil {
+			s = 0
+		}
+		s = s.(int) + int(i.(Int))
+		return true
+	})
+	return s
+}
+
+func checkSummaries(t *testing.T, tree *LLRB, h *Node) interface{} {
+	t.Helper()
+	if h == nil {
+		return nil
+	}
+	expected := tree.augment(h.Item, checkSummaries(t, tree, h.Left), checkSummaries(t, tree, h.Right))
+	if summary(h) != expected {
+		t.Fatalf("bad summary at %v: expecting %v, got %v", h.Item, expected, summary(h))
+	}
+	return expected
+}
+
+func TestAugment(t *testing.T) {
+	tree := New()
+	n := 300
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	tree.SetAugment(sumAugment)
+	checkSummaries(t, tree, tree.root)
+	for i := 0; i < n; i++ {
+		switch i % 5 {
+		case 0:
+			tree.DeleteMin()
+		case 1:
+			tree.DeleteMax()
+		case 2:
+			tree.InsertNoReplace(Int(rand.Intn(n)))
+		case 3:
+			tree.ReplaceOrInsert(Int(rand.Intn(n)))
+		default:
+			tree.Delete(Int(rand.Intn(n)))
+		}
+		checkSummaries(t, tree, tree.root)
+	}
+	if s := tree.Summary(); s != bruteSum(tree, Inf(-1), Inf(1)) {
+		t.Errorf("bad total summary %v", s)
+	}
+	for i := 0; i < 1000; i++ {
+		lo, hi := Int(rand.Intn(n+10)-5), Int(rand.Intn(n+10)-5)
+		if got, expected := tree.Aggregate(lo, hi), bruteSum(tree, lo, hi); got != expected {
+			t.Fatalf("aggregate of [%d, %d): expecting %v, got %v", lo, hi, expected, got)
+		}
+	}
+	if got, expected := tree.Aggregate(Inf(-1), Int(n/2)), bruteSum(tree, Inf(-1), Int(n/2)); got != expected {
+		t.Errorf("aggregate with infinite bound: expecting %v, got %v", expected, got)
+	}
+}
+
+func TestAugmentMinMax(t *testing.T) {
+	type bounds struct{ min, max int }
+	// The summary of a run of items is the range of their weights
+	weight := func(i Item) int { return int(i.(Int)) * 7919 % 1009 }
+	tree := New()
+	tree.SetAugment(func(item Item, left, right interface{}) interface{} {
+		w := weight(item)
+		b := bounds{w, w}
+		for _, s := range []interface{}{left, right} {
+			if s == nil {
+				continue
+			}
+			c := s.(bounds)
+			if c.min < b.min {
+				b.min = c.min
+			}
+			if c.max > b.max {
+				b.max = c.max
+			}
+		}
+		return b
+	})
+	for _, i := range rand.Perm(1000) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	for i := 0; i < 200; i++ {
+		lo := rand.Intn(1000)
+		hi := lo + 1 + rand.Intn(1000-lo)
+		expected := bounds{1 << 30, -1}
+		for j := lo; j < hi; j++ {
+			w := weight(Int(j))
+			if w < expected.min {
+				expected.min = w
+			}
+			if w > expected.max {
+				expected.max = w
+			}
+		}
+		if got := tree.Aggregate(Int(lo), Int(hi)); got != expected {
+			t.Fatalf("aggregate of [%d, %d): expecting %v, got %v", lo, hi, expected, got)
+		}
+	}
+	if tree.Aggregate(Int(5), Int(5)) != nil {
+		t.Errorf("expecting nil aggregate of an empty range")
+	}
+}
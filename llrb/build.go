@@ -0,0 +1,93 @@
+package llrb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsorted is returned when items that must be in ascending order are not.
+var ErrUnsorted = errors.New("llrb: items are not sorted")
+
+// NewFromSorted allocates a new tree, whose items implement Lesser, holding items.
+// See LoadSorted.
+func NewFromSorted(items []Item, allowDuplicates bool) (*LLRB, error) {
+	t := New()
+	if err := t.LoadSorted(items, allowDuplicates); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// LoadSorted replaces the contents of t with items in O(n) time, which is faster
+// than inserting them one by one. Items must be in ascending order, and if
+// allowDuplicates is not set, they must also be distinct; otherwise LoadSorted
+// returns an error wrapping ErrUnsorted and leaves t unchanged. Equal items are
+// kept in the order given, as if inserted with InsertNoReplace.
+func (t *LLRB) LoadSorted(items []Item, allowDuplicates bool) error {
+	for i := range items {
+		if items[i] == nil {
+			panic("inserting nil item")
+		}
+		if i == 0 {
+			continue
+		}
+		if t.less(items[i], items[i-1]) || (!allowDuplicates && !t.less(items[i-1], items[i])) {
+			return fmt.Errorf("%w: item %d is out of order", ErrUnsorted, i)
+		}
+	}
+	t.mods++
+	t.root = t.build(items)
+	t.count = len(items)
+	return nil
+}
+
+// build returns the root of a valid LLRB holding items, which must be in order,
+// in time linear in len(items).
+//
+// The tree is shaped as a 2-3 tree of the largest black height bh for which
+// 2^bh-1 <= len(items). A subtree of black height b holds between 2^b-1 items
+// (all 2-nodes) and 3^b-1 items (all 3-nodes), so each node becomes a 2-node if
+// its items fit under two children of black height b-1, and a 3-node otherwise.
+func (t *LLRB) build(items []Item) *Node {
+	bh := 0
+	for 1<<(bh+1)-1 <= len(items) {
+		bh++
+	}
+	capacity := make([]int, bh+1) // capacity[b] is 3^b-1
+	for b, p := 0, 1; b <= bh; b, p = b+1, p*3 {
+		capacity[b] = p - 1
+	}
+	return t.buildBlack(items, bh, capacity)
+}
+
+// buildBlack returns a black-rooted subtree of black height bh holding items.
+func (t *LLRB) buildBlack(items []Item, bh int, capacity []int) *Node {
+	n := len(items)
+	if n == 0 {
+		return nil
+	}
+	sub := capacity[bh-1]
+	if n-1 <= 2*sub {
+		// 2-node
+		l := n / 2
+		h := t.newNode(items[l])
+		h.Black = true
+		h.Left = t.buildBlack(items[:l], bh-1, capacity)
+		h.Right = t.buildBlack(items[l+1:], bh-1, capacity)
+		t.update(h)
+		return h
+	}
+	// 3-node: a black node with a red left child, over three subtrees
+	m := n - 2
+	a, b := (m+2)/3, (m+1)/3
+	x := t.newNode(items[a])
+	x.Left = t.buildBlack(items[:a], bh-1, capacity)
+	x.Right = t.buildBlack(items[a+1:a+1+b], bh-1, capacity)
+	t.update(x)
+	h := t.newNode(items[a+1+b])
+	h.Black = true
+	h.Left = x
+	h.Right = t.buildBlack(items[a+2+b:], bh-1, capacity)
+	t.update(h)
+	return h
+}
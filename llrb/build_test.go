@@ -0,0 +1,118 @@
+package llrb
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		items := make([]Item, n)
+		for i := range items {
+			items[i] = Int(i)
+		}
+		tree := New()
+		tree.root, tree.count = tree.build(items), n
+		checkLLRB(t, tree)
+		for i := 0; i < n; i++ {
+			if !tree.Has(Int(i)) {
+				t.Fatalf("n=%d: missing %d", n, i)
+			}
+		}
+	}
+}
+
+func TestNewFromSorted(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 100, 1000, 4095} {
+		items := make([]Item, n)
+		for i := range items {
+			items[i] = Int(i)
+		}
+		tree, err := NewFromSorted(items, false)
+		if err != nil {
+			t.Fatalf("n=%d: %v", n, err)
+		}
+		checkLLRB(t, tree)
+		for i := 0; i < n; i++ {
+			if !tree.Has(Int(i)) {
+				t.Fatalf("n=%d: missing %d", n, i)
+			}
+		}
+		// The result is an ordinary tree
+		tree.ReplaceOrInsert(Int(n))
+		tree.Delete(Int(0))
+		checkLLRB(t, tree)
+	}
+}
+
+func TestNewFromSortedDuplicates(t *testing.T) {
+	var items []Item
+	for i := 0; i < 100; i++ {
+		for c := 0; c < 1+i%3; c++ {
+			items = append(items, seqItem{i, c})
+		}
+	}
+	if _, err := NewFromSorted(items, false); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expecting ErrUnsorted for duplicates, got %v", err)
+	}
+	tree, err := NewFromSorted(items, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != len(items) {
+		t.Fatalf("expecting len %d, got %d", len(items), tree.Len())
+	}
+	if n := tree.Count(seqItem{key: 50}); n != 3 {
+		t.Fatalf("expecting 3 copies of 50, got %d", n)
+	}
+	checkLLRB(t, tree)
+	for c, item := range tree.GetAll(seqItem{key: 50}) {
+		if item != (seqItem{50, c}) {
+			t.Errorf("equal items out of order: %v", tree.GetAll(seqItem{key: 50}))
+		}
+	}
+}
+
+func TestNewFromSortedUnsorted(t *testing.T) {
+	items := []Item{Int(1), Int(2), Int(4), Int(3)}
+	if _, err := NewFromSorted(items, true); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("expecting ErrUnsorted, got %v", err)
+	}
+	tree := NewWith(lessInt)
+	tree.ReplaceOrInsert(10)
+	if err := tree.LoadSorted([]Item{3, 2}, false); err == nil || tree.Len() != 1 || !tree.Has(10) {
+		t.Errorf("failed load modified the tree")
+	}
+}
+
+func TestLoadSortedAugmented(t *testing.T) {
+	tree := New()
+	tree.EnableRank()
+	tree.SetAugment(sumAugment)
+	perm := rand.Perm(1000)
+	items := make([]Item, len(perm))
+	for i := range items {
+		items[i] = Int(i)
+	}
+	if err := tree.LoadSorted(items, false); err != nil {
+		t.Fatal(err)
+	}
+	checkLLRB(t, tree)
+	checkSummaries(t, tree, tree.root)
+	if tree.Select(perm[0]) != Int(perm[0]) {
+		t.Errorf("bad sizes after load")
+	}
+}
+
+func BenchmarkNewFromSorted(b *testing.B) {
+	b.StopTimer()
+	items := make([]Item, 10000)
+	for i := range items {
+		items[i] = Int(i)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		NewFromSorted(items, false)
+	}
+}
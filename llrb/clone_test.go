@@ -0,0 +1,123 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// mutateRandomly applies n random modifications to tree, keys in [0, keys)
+func mutateRandomly(tree *LLRB, n, keys int) {
+	for i := 0; i < n; i++ {
+		switch rand.Intn(5) {
+		case 0:
+			tree.ReplaceOrInsert(Int(rand.Intn(keys)))
+		case 1:
+			tree.InsertNoReplace(Int(rand.Intn(keys)))
+		case 2:
+			tree.Delete(Int(rand.Intn(keys)))
+		case 3:
+			tree.DeleteMin()
+		default:
+			tree.DeleteMax()
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	n := 1000
+	tree := New()
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	clone := tree.Clone()
+	original := collect(tree.Ascend)
+	mutateRandomly(clone, n, n)
+	checkLLRB(t, clone)
+	if got := collect(tree.Ascend); !reflect.DeepEqual(got, original) {
+		t.Fatalf("modifying the clone changed the original")
+	}
+	if tree.Len() != n {
+		t.Errorf("expecting original len %d, got %d", n, tree.Len())
+	}
+	cloned := collect(clone.Ascend)
+	mutateRandomly(tree, n, n)
+	checkLLRB(t, tree)
+	if got := collect(clone.Ascend); !reflect.DeepEqual(got, cloned) {
+		t.Fatalf("modifying the original changed the clone")
+	}
+	if clone.Len() != len(cloned) {
+		t.Errorf("expecting clone len %d, got %d", len(cloned), clone.Len())
+	}
+}
+
+func TestCloneOfClone(t *testing.T) {
+	tree := New()
+	tree.EnableRank()
+	for _, i := range rand.Perm(200) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	trees := []*LLRB{tree}
+	var contents [][]Item
+	for i := 0; i < 8; i++ {
+		trees = append(trees, trees[rand.Intn(len(trees))].Clone())
+	}
+	for _, c := range trees {
+		mutateRandomly(c, 100, 300)
+		contents = append(contents, collect(c.Ascend))
+	}
+	for i, c := range trees {
+		checkLLRB(t, c)
+		if got := collect(c.Ascend); !reflect.DeepEqual(got, contents[i]) {
+			t.Errorf("tree %d was changed through another clone", i)
+		}
+	}
+}
+
+func TestFreshTreesOwnNoNodes(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(100) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	p := tree.Snapshot()
+	before := collect(p.tree.Ascend)
+	// A fresh tree must copy the nodes it is given before modifying them.
+	other := New()
+	other.SetRoot(p.tree.Root())
+	other.count = p.Len()
+	mutateRandomly(other, 200, 100)
+	checkLLRB(t, other)
+	if got := collect(p.tree.Ascend); !reflect.DeepEqual(got, before) {
+		t.Errorf("modifying a fresh tree changed the snapshot it was built from")
+	}
+}
+
+func TestCloneUnderReadLock(t *testing.T) {
+	tree := intTree(false, rand.Perm(1000)...)
+	items := collect(tree.Ascend)
+	var mu sync.RWMutex
+	clones := make([]*LLRB, 4)
+	var wg sync.WaitGroup
+	for i := range clones {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.RLock()
+			defer mu.RUnlock()
+			clones[i] = tree.Clone()
+		}(i)
+	}
+	wg.Wait()
+	mu.Lock()
+	mutateRandomly(tree, 500, 1000)
+	mu.Unlock()
+	checkLLRB(t, tree)
+	for i, c := range clones {
+		if got := collect(c.Ascend); !reflect.DeepEqual(got, items) {
+			t.Fatalf("modifying the tree changed clone %d", i)
+		}
+		mutateRandomly(c, 100, 1000)
+		checkLLRB(t, c)
+	}
+}
@@ -0,0 +1,178 @@
+package llrb
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Concurrent is an ordered collection whose readers never wait. It holds a
+// current version of the tree as a Persistent, which readers load atomically
+// and query without any locking, seeing a consistent snapshot for as long as
+// they keep it. Writers are serialized by a mutex; each builds a new version by
+// path copying, which costs O(log n) allocations per modification, and then
+// publishes it atomically in place of the current one.
+//
+// The query methods of Concurrent each load the current version afresh, so two
+// calls may observe different versions. To run several queries against the
+// same version, call Load once and query the returned Persistent.
+type Concurrent struct {
+	mu      sync.Mutex   // Serializes writers
+	current atomic.Value // The current *Persistent
+}
+
+// NewConcurrent allocates a new empty concurrent tree whose items implement Lesser.
+func NewConcurrent() *Concurrent {
+	c := &Concurrent{}
+	c.current.Store(NewPersistent())
+	return c
+}
+
+// NewConcurrentWith allocates a new empty concurrent tree that orders its items with less.
+func NewConcurrentWith(less LessFunc) *Concurrent {
+	c := &Concurrent{}
+	c.current.Store(NewPersistentWith(less))
+	return c
+}
+
+// Load returns the current version of the tree in O(1) time, without locking.
+func (c *Concurrent) Load() *Persistent {
+	return c.current.Load().(*Persistent)
+}
+
+// Update calls f with a private working copy of the current version, under the
+// writers' lock, and publishes the result as the new version when f returns.
+// Readers see either none or all of the modifications made by f. If f panics,
+// nothing is published. F must not retain the tree after returning.
+func (c *Concurrent) Update(f func(t *LLRB)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	q := c.Load().next()
+	f(&q.tree)
+	c.current.Store(q)
+}
+
+// ReplaceOrInsert inserts item into the tree. If an existing
+// element has the same order, it is removed from the tree and returned.
+func (c *Concurrent) ReplaceOrInsert(item Item) Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, replaced := c.Load().ReplaceOrInsert(item)
+	c.current.Store(p)
+	return replaced
+}
+
+// InsertNoReplace inserts item into the tree. If an existing
+// element has the same order, both elements remain in the tree.
+func (c *Concurrent) InsertNoReplace(item Item) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current.Store(c.Load().InsertNoReplace(item))
+}
+
+// Delete deletes an item from the tree whose key equals key.
+// The deleted item is returned, otherwise nil is returned.
+func (c *Concurrent) Delete(key Item) Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, deleted := c.Load().Delete(key)
+	c.current.Store(p)
+	return deleted
+}
+
+// DeleteMin deletes the minimum element in the tree and returns it, or nil if the tree is empty.
+func (c *Concurrent) DeleteMin() Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, deleted := c.Load().DeleteMin()
+	c.current.Store(p)
+	return deleted
+}
+
+// DeleteMax deletes the maximum element in the tree and returns it, or nil if the tree is empty.
+func (c *Concurrent) DeleteMax() Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, deleted := c.Load().DeleteMax()
+	c.current.Store(p)
+	return deleted
+}
+
+// Len returns the number of nodes in the tree.
+func (c *Concurrent) Len() int { return c.Load().Len() }
+
+// Has returns true if the tree contains an element whose order is the same as that of key.
+func (c *Concurrent) Has(key Item) bool { return c.Load().Has(key) }
+
+// Get retrieves an element from the tree whose order is the same as that of key.
+func (c *Concurrent) Get(key Item) Item { return c.Load().Get(key) }
+
+// Min returns the minimum element in the tree.
+func (c *Concurrent) Min() Item { return c.Load().Min() }
+
+// Max returns the maximum element in the tree.
+func (c *Concurrent) Max() Item { return c.Load().Max() }
+
+// Floor returns the greatest element in the tree that is less than or equal to key.
+func (c *Concurrent) Floor(key Item) Item { return c.Load().Floor(key) }
+
+// Ceiling returns the least element in the tree that is greater than or equal to key.
+func (c *Concurrent) Ceiling(key Item) Item { return c.Load().Ceiling(key) }
+
+// Lower returns the greatest element in the tree that is strictly less than key.
+func (c *Concurrent) Lower(key Item) Item { return c.Load().Lower(key) }
+
+// Higher returns the least element in the tree that is strictly greater than key.
+func (c *Concurrent) Higher(key Item) Item { return c.Load().Higher(key) }
+
+// The traversals below visit the version that is current when they start. The
+// iterator may modify the tree, which affects later versions only.
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (c *Concurrent) Ascend(iterator ItemIterator) {
+	c.Load().Ascend(iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (c *Concurrent) Descend(iterator ItemIterator) {
+	c.Load().Descend(iterator)
+}
+
+// AscendRange will call iterator once for each element greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (c *Concurrent) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	c.Load().AscendRange(greaterOrEqual, lessThan, iterator)
+}
+
+// AscendGreaterOrEqual will call iterator once for each element greater or equal to
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (c *Concurrent) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
+	c.Load().AscendGreaterOrEqual(pivot, iterator)
+}
+
+// AscendLessThan will call iterator once for each element lower than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (c *Concurrent) AscendLessThan(pivot Item, iterator ItemIterator) {
+	c.Load().AscendLessThan(pivot, iterator)
+}
+
+// DescendRange will call iterator once for each element less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (c *Concurrent) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	c.Load().DescendRange(lessOrEqual, greaterThan, iterator)
+}
+
+// DescendLessOrEqual will call iterator once for each element less than or equal to
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (c *Concurrent) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
+	c.Load().DescendLessOrEqual(pivot, iterator)
+}
+
+// Range will call iterator once for each element between the bounds lo and hi,
+// in the order given by dir. It will stop whenever the iterator returns false.
+func (c *Concurrent) Range(lo, hi Bound, dir Direction, iterator ItemIterator) {
+	c.Load().Range(lo, hi, dir, iterator)
+}
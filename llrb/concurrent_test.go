@@ -0,0 +1,110 @@
+package llrb
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestConcurrent(t *testing.T) {
+	c := NewConcurrent()
+	const writers, n = 4, 300
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, i := range rand.Perm(n) {
+				k := 2 * (i*writers + w)
+				// Each pair of items becomes visible at once
+				c.Update(func(tree *LLRB) {
+					tree.ReplaceOrInsert(Int(k))
+					tree.ReplaceOrInsert(Int(k + 1))
+				})
+				if i%3 == 0 {
+					c.Update(func(tree *LLRB) {
+						tree.Delete(Int(k))
+						tree.Delete(Int(k + 1))
+					})
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				p := c.Load()
+				if p.Len()%2 != 0 {
+					t.Errorf("observed a partial update: %d items", p.Len())
+				}
+				var prev Item
+				p.Ascend(func(i Item) bool {
+					if prev != nil && prev.(Int) >= i.(Int) {
+						t.Errorf("out of order: %v after %v", i, prev)
+					}
+					if i.(Int)%2 == 0 && !p.Has(i.(Int)+1) {
+						t.Errorf("%v is missing its pair", i)
+					}
+					prev = i
+					return true
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	if expected := 2 * writers * (n - (n+2)/3); c.Len() != expected {
+		t.Errorf("expected %d items but got %d", expected, c.Len())
+	}
+	p := c.Load()
+	checkLLRB(t, &p.tree)
+}
+
+func TestConcurrentVersions(t *testing.T) {
+	c := NewConcurrentWith(lessInt)
+	for i := 0; i < 10; i++ {
+		c.ReplaceOrInsert(i)
+	}
+	old := c.Load()
+	if c.Delete(3) != 3 || c.DeleteMin() != 0 || c.DeleteMax() != 9 {
+		t.Errorf("unexpected deletions")
+	}
+	c.InsertNoReplace(5)
+	if c.Len() != 8 || old.Len() != 10 || !old.Has(3) || c.Has(3) {
+		t.Errorf("expected the old version to be unaffected, got %d and %d items", c.Len(), old.Len())
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expecting a panic")
+			}
+		}()
+		c.Update(func(tree *LLRB) {
+			tree.DeleteMin()
+			panic("abort")
+		})
+	}()
+	if c.Len() != 8 || c.Min() != 1 {
+		t.Errorf("expected an aborted update to publish nothing")
+	}
+	// The traversals visit the version current when they start
+	n := 0
+	c.Ascend(func(i Item) bool {
+		c.Delete(i)
+		n++
+		return true
+	})
+	if n != 8 || c.Len() != 0 {
+		t.Errorf("expected 8 visits and an empty tree, got %d and %d", n, c.Len())
+	}
+}
@@ -0,0 +1,131 @@
+package llrb
+
+// Cursor is a position in an LLRB that can be moved forwards and backwards
+// one element at a time. It keeps the path from the root down to its current
+// node on an explicit stack, so moving it takes no recursion and allocates
+// only when the stack grows beyond the deepest path seen so far.
+//
+// A cursor is only valid for the tree as it was when the cursor was last
+// positioned by SeekFirst, SeekLast or Seek. If the tree is modified, Item,
+// Next and Prev panic until the cursor is repositioned with one of the Seek
+// methods.
+type Cursor struct {
+	t     *LLRB
+	stack []*Node // The path from the root to the current node
+	mods  uint64  // The modification count of t when the cursor was positioned
+}
+
+// Cursor returns a new cursor on t. It is not valid until it is positioned
+// with SeekFirst, SeekLast or Seek.
+func (t *LLRB) Cursor() *Cursor {
+	return &Cursor{t: t}
+}
+
+// Valid returns true if the cursor is positioned at an element.
+func (c *Cursor) Valid() bool { return len(c.stack) > 0 }
+
+// Item returns the element at the cursor, or nil if the cursor is not valid.
+func (c *Cursor) Item() Item {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	c.t.checkUnmodified(c.mods)
+	return c.stack[len(c.stack)-1].Item
+}
+
+// SeekFirst moves the cursor to the minimum element in the tree.
+// It returns false if the tree is empty.
+func (c *Cursor) SeekFirst() bool {
+	c.stack, c.mods = c.stack[:0], c.t.mods
+	for h := c.t.root; h != nil; h = h.Left {
+		c.stack = append(c.stack, h)
+	}
+	return c.Valid()
+}
+
+// SeekLast moves the cursor to the maximum element in the tree.
+// It returns false if the tree is empty.
+func (c *Cursor) SeekLast() bool {
+	c.stack, c.mods = c.stack[:0], c.t.mods
+	for h := c.t.root; h != nil; h = h.Right {
+		c.stack = append(c.stack, h)
+	}
+	return c.Valid()
+}
+
+// Seek moves the cursor to the least element greater than or equal to key.
+// It returns false, leaving the cursor invalid, if there is no such element.
+func (c *Cursor) Seek(key Item) bool {
+	c.stack, c.mods = c.stack[:0], c.t.mods
+	found := 0
+	for h := c.t.root; h != nil; {
+		c.stack = append(c.stack, h)
+		if c.t.less(h.Item, key) {
+			h = h.Right
+		} else {
+			found = len(c.stack)
+			h = h.Left
+		}
+	}
+	// The path to the ceiling of @key is a prefix of the path searched
+	c.stack = c.stack[:found]
+	return c.Valid()
+}
+
+// Next moves the cursor to the following element in ascending order. It returns
+// false, leaving the cursor invalid, if the cursor was at the maximum element or
+// was not valid.
+func (c *Cursor) Next() bool {
+	if len(c.stack) == 0 {
+		return false
+	}
+	c.t.checkUnmodified(c.mods)
+	h := c.stack[len(c.stack)-1]
+	if h.Right != nil {
+		for h = h.Right; h != nil; h = h.Left {
+			c.stack = append(c.stack, h)
+		}
+		return true
+	}
+	// Climb until we arrive from a left child
+	for {
+		c.stack = c.stack[:len(c.stack)-1]
+		if len(c.stack) == 0 {
+			return false
+		}
+		parent := c.stack[len(c.stack)-1]
+		if parent.Left == h {
+			return true
+		}
+		h = parent
+	}
+}
+
+// Prev moves the cursor to the preceding element in ascending order. It returns
+// false, leaving the cursor invalid, if the cursor was at the minimum element or
+// was not valid.
+func (c *Cursor) Prev() bool {
+	if len(c.stack) == 0 {
+		return false
+	}
+	c.t.checkUnmodified(c.mods)
+	h := c.stack[len(c.stack)-1]
+	if h.Left != nil {
+		for h = h.Left; h != nil; h = h.Right {
+			c.stack = append(c.stack, h)
+		}
+		return true
+	}
+	// Climb until we arrive from a right child
+	for {
+		c.stack = c.stack[:len(c.stack)-1]
+		if len(c.stack) == 0 {
+			return false
+		}
+		parent := c.stack[len(c.stack)-1]
+		if parent.Right == h {
+			return true
+		}
+		h = parent
+	}
+}
@@ -0,0 +1,113 @@
+package llrb
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCursorWalk(t *testing.T) {
+	tree := New()
+	c := tree.Cursor()
+	if c.Valid() || c.SeekFirst() || c.SeekLast() || c.Seek(Int(0)) || c.Next() || c.Prev() {
+		t.Errorf("expecting an invalid cursor on an empty tree")
+	}
+	if c.Item() != nil {
+		t.Errorf("expecting no item from an invalid cursor")
+	}
+	const n = 200
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	j := 0
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		if c.Item() != Int(j) {
+			t.Fatalf("expected %d but got %v", j, c.Item())
+		}
+		j++
+	}
+	if j != n || c.Valid() {
+		t.Errorf("expected %d items and an invalid cursor, got %d", n, j)
+	}
+	for ok := c.SeekLast(); ok; ok = c.Prev() {
+		j--
+		if c.Item() != Int(j) {
+			t.Fatalf("expected %d but got %v", j, c.Item())
+		}
+	}
+	if j != 0 || c.Valid() {
+		t.Errorf("expected to walk back to 0 and an invalid cursor, got %d", j)
+	}
+}
+
+func TestCursorSeek(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(50) {
+		tree.ReplaceOrInsert(Int(2 * i))
+	}
+	c := tree.Cursor()
+	for k := -1; k <= 100; k++ {
+		ok := c.Seek(Int(k))
+		if expected := tree.Ceiling(Int(k)); c.Item() != expected || ok != (expected != nil) {
+			t.Fatalf("seek %d: expected %v but got %v", k, expected, c.Item())
+		}
+		if !ok {
+			continue
+		}
+		at := c.Item()
+		if c.Next(); c.Item() != tree.Higher(at) {
+			t.Fatalf("next after seek %d: expected %v but got %v", k, tree.Higher(at), c.Item())
+		}
+		c.Seek(Int(k))
+		if c.Prev(); c.Item() != tree.Lower(at) {
+			t.Fatalf("prev after seek %d: expected %v but got %v", k, tree.Lower(at), c.Item())
+		}
+	}
+}
+
+func TestCursorDuplicates(t *testing.T) {
+	tree := New()
+	for i := 0; i < 30; i++ {
+		tree.InsertNoReplace(Int(i % 3))
+	}
+	c := tree.Cursor()
+	counts := make(map[Item]int)
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		counts[c.Item()]++
+	}
+	if len(counts) != 3 || counts[Int(0)] != 10 || counts[Int(1)] != 10 || counts[Int(2)] != 10 {
+		t.Errorf("expected ten of each item, got %v", counts)
+	}
+	n := 0
+	for ok := c.Seek(Int(1)); ok && c.Item() == Int(1); ok = c.Next() {
+		n++
+	}
+	if n != 10 {
+		t.Errorf("expected ten items equal to 1 after seeking, got %d", n)
+	}
+}
+
+func TestCursorModified(t *testing.T) {
+	tree := New()
+	tree.ReplaceOrInsertBulk(Int(1), Int(2), Int(3))
+	c := tree.Cursor()
+	c.Seek(Int(2))
+	tree.Delete(c.Item())
+	for name, f := range map[string]func(){
+		"Item": func() { c.Item() },
+		"Next": func() { c.Next() },
+		"Prev": func() { c.Prev() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expecting a panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+	// Repositioning makes the cursor usable again.
+	if !c.Seek(Int(2)) || c.Item() != Int(3) || !c.Prev() || c.Item() != Int(1) {
+		t.Errorf("expecting a usable cursor after Seek")
+	}
+}
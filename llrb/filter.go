@@ -0,0 +1,52 @@
+package llrb
+
+// DeleteIf deletes every element for which pred returns true, and returns how many
+// were deleted. See DeleteIfInRange.
+func (t *LLRB) DeleteIf(pred func(Item) bool) int {
+	return t.DeleteIfInRange(Inf(-1), Inf(1), pred)
+}
+
+// Retain deletes every element for which pred returns false, and returns how many
+// were deleted. See DeleteIfInRange.
+func (t *LLRB) Retain(pred func(Item) bool) int {
+	return t.RetainInRange(Inf(-1), Inf(1), pred)
+}
+
+// RetainInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns false, and returns how many were deleted.
+// See DeleteIfInRange.
+func (t *LLRB) RetainInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	return t.DeleteIfInRange(greaterOrEqual, lessThan, func(i Item) bool { return !pred(i) })
+}
+
+// DeleteIfInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns true, and returns how many were deleted.
+//
+// Pred is called once for each element in the range, in ascending order, and must
+// not modify the tree. Rather than deleting the matching elements one by one,
+// DeleteIfInRange cuts the range out of the tree, rebuilds it from the remaining
+// elements, and joins it back in, which takes O(log n + k) time for a range of
+// k elements. The tree is left untouched if nothing matches.
+func (t *LLRB) DeleteIfInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	var kept []Item
+	n := 0
+	t.ascendRange(t.root, greaterOrEqual, lessThan, t.guard(func(i Item) bool {
+		if pred(i) {
+			n++
+		} else {
+			kept = append(kept, i)
+		}
+		return true
+	}))
+	if n == 0 {
+		return 0
+	}
+	t.mods++
+	l, lbh, r, rbh := t.split(t.root, blackHeight(t.root), greaterOrEqual)
+	_, _, r, rbh = t.split(r, rbh, lessThan)
+	m := t.build(kept)
+	l, lbh = t.concat(l, lbh, m, blackHeight(m))
+	t.root, _ = t.concat(l, lbh, r, rbh)
+	t.count -= n
+	return n
+}
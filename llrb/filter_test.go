@@ -0,0 +1,79 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDeleteIf(t *testing.T) {
+	for trial := 0; trial < 200; trial++ {
+		n := rand.Intn(300)
+		tree := intTree(trial%2 == 0, rand.Perm(n)...)
+		tree.SetAugment(sumAugment)
+		m := rand.Intn(5) + 1
+		lo, hi := rand.Intn(n+2)-1, rand.Intn(n+2)-1
+		pred := func(i Item) bool { return int(i.(Int))%m == 0 }
+		var expected []Item
+		for _, i := range collect(tree.Ascend) {
+			if !(int(i.(Int)) >= lo && int(i.(Int)) < hi && pred(i)) {
+				expected = append(expected, i)
+			}
+		}
+		deleted := tree.DeleteIfInRange(Int(lo), Int(hi), pred)
+		checkLLRB(t, tree)
+		checkSummaries(t, tree, tree.root)
+		if deleted != n-len(expected) {
+			t.Fatalf("expected %d deletions but got %d", n-len(expected), deleted)
+		}
+		if items := collect(tree.Ascend); !reflect.DeepEqual(items, expected) {
+			t.Fatalf("expected %v but got %v", expected, items)
+		}
+	}
+}
+
+func TestDeleteIfRetain(t *testing.T) {
+	tree := intTree(false, rand.Perm(100)...)
+	even := func(i Item) bool { return i.(Int)%2 == 0 }
+	if n := tree.DeleteIf(func(Item) bool { return false }); n != 0 || tree.Len() != 100 {
+		t.Errorf("expected no deletions, got %d", n)
+	}
+	if n := tree.Retain(even); n != 50 {
+		t.Errorf("expected 50 deletions but got %d", n)
+	}
+	checkLLRB(t, tree)
+	tree.Ascend(func(i Item) bool {
+		if !even(i) {
+			t.Errorf("expected only even items, got %v", i)
+		}
+		return true
+	})
+	if n := tree.RetainInRange(Int(10), Int(20), func(i Item) bool { return i == Int(14) }); n != 4 {
+		t.Errorf("expected 4 deletions but got %d", n)
+	}
+	if n := tree.DeleteIf(even); n != 46 || tree.Len() != 0 || tree.Root() != nil {
+		t.Errorf("expected an empty tree after 46 deletions, got %d deletions and %d items", n, tree.Len())
+	}
+}
+
+func TestDeleteIfMultiset(t *testing.T) {
+	tree := New()
+	for i := 0; i < 60; i++ {
+		tree.InsertNoReplace(seqItem{i % 3, i})
+	}
+	n := tree.DeleteIf(func(i Item) bool { return i.(seqItem).seq%2 == 0 })
+	if n != 30 || tree.Len() != 30 {
+		t.Errorf("expected 30 deletions and 30 items, got %d and %d", n, tree.Len())
+	}
+	checkLLRB(t, tree)
+	for key := 0; key < 3; key++ {
+		prev := -1
+		for _, i := range tree.GetAll(seqItem{key: key}) {
+			if s := i.(seqItem).seq; s%2 == 0 || s <= prev {
+				t.Errorf("unexpected item %v after seq %d", i, prev)
+			} else {
+				prev = s
+			}
+		}
+	}
+}
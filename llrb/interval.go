@@ -0,0 +1,120 @@
+package llrb
+
+// Interval is the half-open range [Start, End) of keys of type K, carrying a Value.
+type Interval[K, V any] struct {
+	Start, End K
+	Value      V
+}
+
+// IntervalTree stores intervals ordered by start, then end, in an LLRB whose
+// nodes are augmented with the largest end in their subtree. Queries descend
+// only into subtrees that can hold a match, so they run in O(min(n, (k+1) log n))
+// time when they report k intervals.
+type IntervalTree[K, V any] struct {
+	tree *LLRB
+	less func(a, b K) bool
+}
+
+// NewIntervalTree allocates a new interval tree whose keys are ordered by less.
+func NewIntervalTree[K, V any](less func(a, b K) bool) *IntervalTree[K, V] {
+	if less == nil {
+		panic("nil less function")
+	}
+	it := &IntervalTree[K, V]{less: less}
+	it.tree = NewWith(func(a, b interface{}) bool {
+		x, y := a.(Interval[K, V]), b.(Interval[K, V])
+		if less(x.Start, y.Start) {
+			return true
+		}
+		return !less(y.Start, x.Start) && less(x.End, y.End)
+	})
+	it.tree.SetAugment(func(item Item, left, right interface{}) interface{} {
+		end := item.(Interval[K, V]).End
+		for _, s := range []interface{}{left, right} {
+			if s != nil && less(end, s.(K)) {
+				end = s.(K)
+			}
+		}
+		return end
+	})
+	return it
+}
+
+// Len returns the number of intervals in the tree.
+func (it *IntervalTree[K, V]) Len() int { return it.tree.Len() }
+
+// Insert adds the interval [start, end) carrying value. Intervals with the same
+// bounds may be inserted more than once. Empty intervals are rejected.
+func (it *IntervalTree[K, V]) Insert(start, end K, value V) {
+	if !it.less(start, end) {
+		panic("empty interval")
+	}
+	it.tree.InsertNoReplace(Interval[K, V]{start, end, value})
+}
+
+// Delete removes the first inserted interval with bounds [start, end) and returns
+// its value. The boolean result is false if there was no such interval.
+func (it *IntervalTree[K, V]) Delete(start, end K) (value V, ok bool) {
+	d := it.tree.DeleteExact(Interval[K, V]{Start: start, End: end}, func(a, b Item) bool { return true })
+	if d == nil {
+		return value, false
+	}
+	return d.(Interval[K, V]).Value, true
+}
+
+// Overlapping will call iterator once for each interval that overlaps [lo, hi),
+// in ascending order of start. It will stop whenever the iterator returns false.
+// No interval overlaps an empty query, in which hi is not greater than lo.
+func (it *IntervalTree[K, V]) Overlapping(lo, hi K, iterator func(Interval[K, V]) bool) {
+	if !it.less(lo, hi) {
+		return
+	}
+	it.overlapping(it.tree.root, lo, func(start K) bool { return it.less(start, hi) }, it.guard(iterator))
+}
+
+// Stab will call iterator once for each interval that contains p, in ascending
+// order of start. It will stop whenever the iterator returns false.
+func (it *IntervalTree[K, V]) Stab(p K, iterator func(Interval[K, V]) bool) {
+	it.overlapping(it.tree.root, p, func(start K) bool { return !it.less(p, start) }, it.guard(iterator))
+}
+
+// AnyOverlap returns true if some interval overlaps [lo, hi).
+func (it *IntervalTree[K, V]) AnyOverlap(lo, hi K) bool {
+	found := false
+	it.Overlapping(lo, hi, func(Interval[K, V]) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// guard is like LLRB.guard, for iterators over intervals.
+func (it *IntervalTree[K, V]) guard(iterator func(Interval[K, V]) bool) func(Interval[K, V]) bool {
+	mods := it.tree.mods
+	return func(iv Interval[K, V]) bool {
+		if !iterator(iv) {
+			return false
+		}
+		it.tree.checkUnmodified(mods)
+		return true
+	}
+}
+
+// overlapping visits the intervals in the subtree at h that end after lo
+// and whose start satisfies starts.
+func (it *IntervalTree[K, V]) overlapping(h *Node, lo K, starts func(K) bool, iterator func(Interval[K, V]) bool) bool {
+	if h == nil || !it.less(lo, summary(h).(K)) { // every interval below ends by lo
+		return true
+	}
+	if !it.overlapping(h.Left, lo, starts, iterator) {
+		return false
+	}
+	iv := h.Item.(Interval[K, V])
+	if !starts(iv.Start) { // neither this interval nor those to its right start in time
+		return true
+	}
+	if it.less(lo, iv.End) && !iterator(iv) {
+		return false
+	}
+	return it.overlapping(h.Right, lo, starts, iterator)
+}
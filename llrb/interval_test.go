@@ -0,0 +1,124 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+type span struct{ start, end, id int }
+
+func lessIntKey(a, b int) bool { return a < b }
+
+func collectIntervals(walk func(func(Interval[int, int]) bool)) []int {
+	ids := []int{}
+	walk(func(iv Interval[int, int]) bool {
+		ids = append(ids, iv.Value)
+		return true
+	})
+	return ids
+}
+
+// bruteIntervals returns the ids of the spans selected by match, in ascending order of start
+func bruteIntervals(it *IntervalTree[int, int], spans map[int]span, match func(span) bool) []int {
+	ids := []int{}
+	it.tree.AscendGreaterOrEqual(Inf(-1), func(i Item) bool {
+		if s := spans[i.(Interval[int, int]).Value]; match(s) {
+			ids = append(ids, s.id)
+		}
+		return true
+	})
+	return ids
+}
+
+func TestIntervalTree(t *testing.T) {
+	it := NewIntervalTree[int, int](lessIntKey)
+	spans := map[int]span{}
+	for id := 0; id < 500; id++ {
+		start := rand.Intn(1000)
+		s := span{start, start + 1 + rand.Intn(50), id}
+		spans[id] = s
+		it.Insert(s.start, s.end, s.id)
+	}
+	for id := 0; id < 500; id += 3 {
+		s, ok := spans[id]
+		if !ok { // deleted already, as an earlier span with the same bounds
+			continue
+		}
+		v, ok := it.Delete(s.start, s.end)
+		if !ok || spans[v].start != s.start || spans[v].end != s.end {
+			t.Fatalf("failed to delete %v", s)
+		}
+		delete(spans, v)
+	}
+	if it.Len() != len(spans) {
+		t.Fatalf("expecting len %d, got %d", len(spans), it.Len())
+	}
+	checkLLRB(t, it.tree)
+	for q := 0; q < 500; q++ {
+		lo := rand.Intn(1100) - 50
+		hi := lo + 1 + rand.Intn(30)
+		expected := bruteIntervals(it, spans, func(s span) bool { return s.start < hi && lo < s.end })
+		got := collectIntervals(func(f func(Interval[int, int]) bool) { it.Overlapping(lo, hi, f) })
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("overlapping [%d, %d): expected %v but got %v", lo, hi, expected, got)
+		}
+		if it.AnyOverlap(lo, hi) != (len(expected) > 0) {
+			t.Fatalf("bad AnyOverlap for [%d, %d)", lo, hi)
+		}
+		expected = bruteIntervals(it, spans, func(s span) bool { return s.start <= lo && lo < s.end })
+		got = collectIntervals(func(f func(Interval[int, int]) bool) { it.Stab(lo, f) })
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("stab %d: expected %v but got %v", lo, expected, got)
+		}
+	}
+}
+
+func TestIntervalTreeEdges(t *testing.T) {
+	it := NewIntervalTree[int, string](lessIntKey)
+	it.Insert(10, 20, "a")
+	it.Insert(20, 30, "b")
+	it.Insert(10, 20, "c")
+	var got []string
+	it.Stab(20, func(iv Interval[int, string]) bool {
+		got = append(got, iv.Value)
+		return true
+	})
+	if !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("half-open intervals: expected [b] but got %v", got)
+	}
+	if it.AnyOverlap(0, 10) || it.AnyOverlap(30, 40) || !it.AnyOverlap(19, 20) {
+		t.Errorf("bad overlap at the boundaries")
+	}
+	if it.AnyOverlap(15, 15) || it.AnyOverlap(15, 12) || it.AnyOverlap(25, 5) {
+		t.Errorf("found an overlap with an empty query")
+	}
+	if v, ok := it.Delete(10, 20); !ok || v != "a" {
+		t.Errorf("expecting to delete a, got %q", v)
+	}
+	if _, ok := it.Delete(10, 21); ok {
+		t.Errorf("deleted non-existent interval")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic on an empty interval")
+		}
+	}()
+	it.Insert(5, 5, "empty")
+}
+
+func TestIntervalModifyDuringIteration(t *testing.T) {
+	it := NewIntervalTree[int, int](lessIntKey)
+	for i := 0; i < 10; i++ {
+		it.Insert(i, i+5, i)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic")
+		}
+	}()
+	it.Stab(7, func(iv Interval[int, int]) bool {
+		it.Delete(iv.Start, iv.End)
+		return true
+	})
+}
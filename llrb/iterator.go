@@ -1,23 +1,105 @@
 package llrb
 
+// ItemIterator is called once for each visited element. Returning false stops the
+// traversal. An iterator may modify the tree that it is visiting only if it then
+// returns false; a traversal that is asked to continue after such a modification
+// panics, since it could no longer visit each element exactly once.
 type ItemIterator func(i Item) bool
 
-//func (t *Tree) Ascend(iterator ItemIterator) {
-//	t.AscendGreaterOrEqual(Inf(-1), iterator)
-//}
+// guard wraps iterator so that the traversal panics if iterator modifies t and
+// asks to continue.
+func (t *LLRB) guard(iterator ItemIterator) ItemIterator {
+	mods := t.mods
+	return func(i Item) bool {
+		if !iterator(i) {
+			return false
+		}
+		t.checkUnmodified(mods)
+		return true
+	}
+}
+
+// checkUnmodified panics if t has been modified since its modification count was mods.
+func (t *LLRB) checkUnmodified(mods uint64) {
+	if t.mods != mods {
+		panic("tree modified during iteration")
+	}
+}
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (t *LLRB) Ascend(iterator ItemIterator) {
+	t.ascend(t.root, t.guard(iterator))
+}
+
+func (t *LLRB) ascend(h *Node, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if !t.ascend(h.Left, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.ascend(h.Right, iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (t *LLRB) Descend(iterator ItemIterator) {
+	t.descend(t.root, t.guard(iterator))
+}
+
+func (t *LLRB) descend(h *Node, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if !t.descend(h.Right, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.descend(h.Left, iterator)
+}
+
+// IterAscend returns a function that yields the elements of the tree in ascending
+// order, one per call, and nil once they are exhausted.
+// The tree must not be modified until the iteration is complete; calling the
+// function after a modification panics.
+func (t *LLRB) IterAscend() func() Item {
+	var stack []*Node
+	for h := t.root; h != nil; h = h.Left {
+		stack = append(stack, h)
+	}
+	mods := t.mods
+	return func() Item {
+		if len(stack) == 0 {
+			return nil
+		}
+		t.checkUnmodified(mods)
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for x := h.Right; x != nil; x = x.Left {
+			stack = append(stack, x)
+		}
+		return h.Item
+	}
+}
 
 func (t *LLRB) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
-	t.ascendRange(t.root, greaterOrEqual, lessThan, iterator)
+	t.ascendRange(t.root, greaterOrEqual, lessThan, t.guard(iterator))
 }
 
 func (t *LLRB) ascendRange(h *Node, inf, sup Item, iterator ItemIterator) bool {
 	if h == nil {
 		return true
 	}
-	if !less(h.Item, sup) {
+	if !t.less(h.Item, sup) {
 		return t.ascendRange(h.Left, inf, sup, iterator)
 	}
-	if less(h.Item, inf) {
+	if t.less(h.Item, inf) {
 		return t.ascendRange(h.Right, inf, sup, iterator)
 	}
 
@@ -30,17 +112,38 @@ func (t *LLRB) ascendRange(h *Node, inf, sup Item, iterator ItemIterator) bool {
 	return t.ascendRange(h.Right, inf, sup, iterator)
 }
 
+// AscendGreaterThan will call iterator once for each element greater than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (t *LLRB) AscendGreaterThan(pivot Item, iterator ItemIterator) {
+	t.ascendGreaterThan(t.root, pivot, t.guard(iterator))
+}
+
+func (t *LLRB) ascendGreaterThan(h *Node, pivot Item, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if t.less(pivot, h.Item) {
+		if !t.ascendGreaterThan(h.Left, pivot, iterator) {
+			return false
+		}
+		if !iterator(h.Item) {
+			return false
+		}
+	}
+	return t.ascendGreaterThan(h.Right, pivot, iterator)
+}
+
 // AscendGreaterOrEqual will call iterator once for each element greater or equal to
 // pivot in ascending order. It will stop whenever the iterator returns false.
 func (t *LLRB) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
-	t.ascendGreaterOrEqual(t.root, pivot, iterator)
+	t.ascendGreaterOrEqual(t.root, pivot, t.guard(iterator))
 }
 
 func (t *LLRB) ascendGreaterOrEqual(h *Node, pivot Item, iterator ItemIterator) bool {
 	if h == nil {
 		return true
 	}
-	if !less(h.Item, pivot) {
+	if !t.less(h.Item, pivot) {
 		if !t.ascendGreaterOrEqual(h.Left, pivot, iterator) {
 			return false
 		}
@@ -54,7 +157,7 @@ func (t *LLRB) ascendGreaterOrEqual(h *Node, pivot Item, iterator ItemIterator)
 // AscendLessThan will call iterator once for each element lower than
 // pivot in ascending order. It will stop whenever the iterator returns false.
 func (t *LLRB) AscendLessThan(pivot Item, iterator ItemIterator) {
-	t.ascendLessThan(t.root, pivot, iterator)
+	t.ascendLessThan(t.root, pivot, t.guard(iterator))
 }
 
 func (t *LLRB) ascendLessThan(h *Node, pivot Item, iterator ItemIterator) bool {
@@ -64,7 +167,7 @@ func (t *LLRB) ascendLessThan(h *Node, pivot Item, iterator ItemIterator) bool {
 	if !t.ascendLessThan(h.Left, pivot, iterator) {
 		return false
 	}
-	if less(h.Item, pivot) {
+	if t.less(h.Item, pivot) {
 		if !iterator(h.Item) {
 			return false
 		}
@@ -76,14 +179,14 @@ func (t *LLRB) ascendLessThan(h *Node, pivot Item, iterator ItemIterator) bool {
 // DescendLessOrEqual will call iterator once for each element less than the
 // pivot in descending order. It will stop whenever the iterator returns false.
 func (t *LLRB) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
-	t.descendLessOrEqual(t.root, pivot, iterator)
+	t.descendLessOrEqual(t.root, pivot, t.guard(iterator))
 }
 
 func (t *LLRB) descendLessOrEqual(h *Node, pivot Item, iterator ItemIterator) bool {
 	if h == nil {
 		return true
 	}
-	if less(h.Item, pivot) || !less(pivot, h.Item) {
+	if t.less(h.Item, pivot) || !t.less(pivot, h.Item) {
 		if !t.descendLessOrEqual(h.Right, pivot, iterator) {
 			return false
 		}
@@ -93,3 +196,52 @@ func (t *LLRB) descendLessOrEqual(h *Node, pivot Item, iterator ItemIterator) bo
 	}
 	return t.descendLessOrEqual(h.Left, pivot, iterator)
 }
+
+// DescendRange will call iterator once for each element less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (t *LLRB) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	t.descendRange(t.root, lessOrEqual, greaterThan, t.guard(iterator))
+}
+
+func (t *LLRB) descendRange(h *Node, sup, inf Item, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if t.less(sup, h.Item) {
+		return t.descendRange(h.Left, sup, inf, iterator)
+	}
+	if !t.less(inf, h.Item) {
+		return t.descendRange(h.Right, sup, inf, iterator)
+	}
+
+	if !t.descendRange(h.Right, sup, inf, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.descendRange(h.Left, sup, inf, iterator)
+}
+
+// DescendGreaterThan will call iterator once for each element greater than
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (t *LLRB) DescendGreaterThan(pivot Item, iterator ItemIterator) {
+	t.descendGreaterThan(t.root, pivot, t.guard(iterator))
+}
+
+func (t *LLRB) descendGreaterThan(h *Node, pivot Item, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if !t.descendGreaterThan(h.Right, pivot, iterator) {
+		return false
+	}
+	if t.less(pivot, h.Item) {
+		if !iterator(h.Item) {
+			return false
+		}
+		return t.descendGreaterThan(h.Left, pivot, iterator)
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package llrb
 
 import (
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -109,3 +110,204 @@ func TestAscendLessThan(t *testing.T) {
 		t.Errorf("expected %v but got %v", expected, ary)
 	}
 }
+
+func TestIterAscend(t *testing.T) {
+	tree := New()
+	if next := tree.IterAscend(); next() != nil {
+		t.Errorf("expecting no items from an empty tree")
+	}
+	perm := rand.Perm(100)
+	for _, i := range perm {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	j := 0
+	next := tree.IterAscend()
+	for u := next(); u != nil; u = next() {
+		if u.(Int) != Int(j) {
+			t.Fatalf("expected %d but got %v", j, u)
+		}
+		j++
+	}
+	if j != len(perm) {
+		t.Errorf("expected %d items but got %d", len(perm), j)
+	}
+}
+
+func TestAscendDescend(t *testing.T) {
+	tree := New()
+	if ary := collect(tree.Ascend); ary != nil {
+		t.Errorf("expected no items but got %v", ary)
+	}
+	tree.InsertNoReplace(Int(4))
+	tree.InsertNoReplace(Int(6))
+	tree.InsertNoReplace(Int(1))
+	tree.InsertNoReplace(Int(3))
+	expected := []Item{Int(1), Int(3), Int(4), Int(6)}
+	if ary := collect(tree.Ascend); !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	expected = []Item{Int(6), Int(4), Int(3), Int(1)}
+	if ary := collect(tree.Descend); !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	var ary []Item
+	tree.Descend(func(i Item) bool {
+		ary = append(ary, i)
+		return len(ary) < 2
+	})
+	expected = []Item{Int(6), Int(4)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
+
+func TestAscendGreaterThan(t *testing.T) {
+	tree := New()
+	tree.InsertNoReplace(Int(4))
+	tree.InsertNoReplace(Int(6))
+	tree.InsertNoReplace(Int(1))
+	tree.InsertNoReplace(Int(3))
+	ary := collect(func(i ItemIterator) { tree.AscendGreaterThan(Int(-1), i) })
+	expected := []Item{Int(1), Int(3), Int(4), Int(6)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.AscendGreaterThan(Int(3), i) })
+	expected = []Item{Int(4), Int(6)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.AscendGreaterThan(Int(2), i) })
+	expected = []Item{Int(3), Int(4), Int(6)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
+
+func TestDescendGreaterThan(t *testing.T) {
+	tree := New()
+	tree.InsertNoReplace(Int(4))
+	tree.InsertNoReplace(Int(6))
+	tree.InsertNoReplace(Int(1))
+	tree.InsertNoReplace(Int(3))
+	ary := collect(func(i ItemIterator) { tree.DescendGreaterThan(Int(-1), i) })
+	expected := []Item{Int(6), Int(4), Int(3), Int(1)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.DescendGreaterThan(Int(3), i) })
+	expected = []Item{Int(6), Int(4)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.DescendGreaterThan(Int(5), i) })
+	expected = []Item{Int(6)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
+
+func TestDescendRange(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(10) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	ary := collect(func(i ItemIterator) { tree.DescendRange(Int(7), Int(3), i) })
+	expected := []Item{Int(7), Int(6), Int(5), Int(4)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.DescendRange(Inf(1), Int(7), i) })
+	expected = []Item{Int(9), Int(8)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = collect(func(i ItemIterator) { tree.DescendRange(Int(3), Int(3), i) })
+	if ary != nil {
+		t.Errorf("expected no items but got %v", ary)
+	}
+	ary = nil
+	tree.DescendRange(Int(9), Inf(-1), func(i Item) bool {
+		ary = append(ary, i)
+		return i != Int(5)
+	})
+	expected = []Item{Int(9), Int(8), Int(7), Int(6), Int(5)}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
+
+func TestModifyDuringIteration(t *testing.T) {
+	walks := map[string]func(*LLRB, ItemIterator){
+		"Ascend":     func(t *LLRB, i ItemIterator) { t.Ascend(i) },
+		"Descend":    func(t *LLRB, i ItemIterator) { t.Descend(i) },
+		"Range":      func(t *LLRB, i ItemIterator) { t.Range(Unbounded(), Unbounded(), Descending, i) },
+		"AscendEq":   func(t *LLRB, i ItemIterator) { t.AscendEqual(Int(5), i) },
+		"AscendFrom": func(t *LLRB, i ItemIterator) { t.AscendGreaterOrEqual(Int(3), i) },
+	}
+	for name, walk := range walks {
+		tree := New()
+		for i := 0; i < 10; i++ {
+			tree.InsertNoReplace(Int(i))
+			tree.InsertNoReplace(Int(5))
+		}
+		// Modifying the tree and stopping is allowed.
+		walk(tree, func(i Item) bool {
+			tree.Delete(i)
+			return false
+		})
+		checkLLRB(t, tree)
+		if tree.Len() != 19 {
+			t.Errorf("%s: expected 19 items but got %d", name, tree.Len())
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expecting a panic", name)
+				}
+			}()
+			walk(tree, func(i Item) bool {
+				tree.Delete(i)
+				return true
+			})
+		}()
+	}
+}
+
+func TestModifyDuringIterAscend(t *testing.T) {
+	tree := New()
+	tree.ReplaceOrInsertBulk(Int(1), Int(2), Int(3))
+	next := tree.IterAscend()
+	next()
+	tree.ReplaceOrInsert(Int(4))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic")
+		}
+	}()
+	next()
+}
+
+func TestReconfigureDuringIteration(t *testing.T) {
+	reconfigure := map[string]func(*LLRB){
+		"EnableRank":      func(t *LLRB) { t.EnableRank() },
+		"SetAugment":      func(t *LLRB) { t.SetAugment(sumAugment) },
+		"SetAugment(nil)": func(t *LLRB) { t.SetAugment(nil) },
+	}
+	for name, f := range reconfigure {
+		tree := New()
+		tree.ReplaceOrInsertBulk(Int(1), Int(2), Int(3))
+		tree.SetAugment(sumAugment)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expecting a panic", name)
+				}
+			}()
+			tree.Ascend(func(Item) bool {
+				f(tree)
+				return true
+			})
+		}()
+	}
+}
@@ -16,10 +16,17 @@
 //
 package llrb
 
+import "sync/atomic"
+
 // Tree is a Left-Leaning Red-Black (LLRB) implementation of 2-3 trees
 type LLRB struct {
-	count int
-	root  *Node
+	count    int
+	root     *Node
+	lessFunc LessFunc
+	ranked   bool        // If set, every node tracks the size of its subtree
+	augment  AugmentFunc // If set, every node tracks the summary of its subtree
+	cow      *cowToken   // Nodes carrying a different token are shared and must be copied before a write
+	mods     uint64      // Incremented by every modification, so that iterators can detect them
 }
 
 type Node struct {
@@ -27,23 +34,99 @@ type Node struct {
 	Left, Right *Node // Pointers to left and right child nodes
 	Black       bool  // If set, the color of the link (incoming from the parent) is black
 	// In the LLRB, new nodes are always red, hence the zero-value for node
+	aug *nodeAug  // Allocated in ranked or augmented trees only
+	cow *cowToken // Token of the tree that may modify the node in place
 }
 
-type Item interface {
-	Less(than Item) bool
+// nodeAug holds the fields of a node that only ranked or augmented trees maintain,
+// so that nodes of other trees do not pay for them. It belongs to a single node,
+// and is copied along with it.
+type nodeAug struct {
+	size    int         // Number of nodes in the subtree, maintained in ranked trees
+	summary interface{} // Summary of the subtree, maintained in augmented trees
 }
 
+// cowToken identifies the nodes that a tree owns. A tree modifies its own nodes in
+// place, and copies nodes carrying any other token, which it shares with other
+// trees, before it writes to them. Every tree is created with a token of its own.
 //
-func less(x, y Item) bool {
-	if x == pinf {
+// Snapshot and Clone share the nodes of a tree without writing to the tree, so
+// that they may run under a read lock: they only mark its token as shared, and
+// the next write to the tree replaces the token before modifying any node.
+type cowToken struct {
+	shared int32 // Set once other trees share the nodes carrying the token; accessed atomically
+}
+
+// share marks the nodes that t owns as shared with another tree.
+func (t *LLRB) share() {
+	if t.cow != nil {
+		atomic.StoreInt32(&t.cow.shared, 1)
+	}
+}
+
+// token returns the token of t, first replacing it if the nodes that carry it are shared.
+func (t *LLRB) token() *cowToken {
+	if t.cow == nil || atomic.LoadInt32(&t.cow.shared) != 0 {
+		t.cow = new(cowToken)
+	}
+	return t.cow
+}
+
+// Item is an element stored in the tree. Items stored in a tree created with New
+// must implement Lesser. Trees created with NewWith accept any value understood
+// by their LessFunc.
+type Item interface{}
+
+// Lesser is implemented by items that know their own order.
+type Lesser interface {
+	Less(than Item) bool
+}
+
+// Comparer is optionally implemented by items of trees created with New, in addition
+// to Lesser. Compare returns a negative number, zero or a positive number when the
+// receiver sorts before, together with, or after than. When present, it is used to
+// descend the tree with one comparison per level instead of two.
+type Comparer interface {
+	Compare(than Item) int
+}
+
+// LessFunc reports whether a sorts before b.
+type LessFunc func(a, b interface{}) bool
+
+// less orders x and y using the tree's LessFunc, if any, or the Less method of x.
+// The Inf sentinels are handled here and are never passed to either.
+func (t *LLRB) less(x, y Item) bool {
+	if x == pinf || y == ninf {
 		return false
 	}
-	if x == ninf {
+	if x == ninf || y == pinf {
 		return true
 	}
-	return x.Less(y)
+	if t.lessFunc != nil {
+		return t.lessFunc(x, y)
+	}
+	return x.(Lesser).Less(y)
 }
 
+// compare is the three-way counterpart of less. It uses Compare for items that
+// implement Comparer and falls back to two calls to less otherwise.
+func (t *LLRB) compare(x, y Item) int {
+	if t.lessFunc == nil && !isInf(x) && !isInf(y) {
+		if c, ok := x.(Comparer); ok {
+			return c.Compare(y)
+		}
+	}
+	switch {
+	case t.less(x, y):
+		return -1
+	case t.less(y, x):
+		return 1
+	}
+	return 0
+}
+
+func isInf(x Item) bool { return x == pinf || x == ninf }
+
 // Inf returns an Item that is "bigger than" any other item, if sign is positive.
 // Otherwise  it returns an Item that is "smaller than" any other item.
 func Inf(sign int) Item {
@@ -73,15 +156,29 @@ func (pInf) Less(Item) bool {
 	return false
 }
 
-// New allocates a new tree
+// New allocates a new tree whose items implement Lesser.
 func New() *LLRB {
-	return &LLRB{}
+	return &LLRB{cow: new(cowToken)}
+}
+
+// NewWith allocates a new tree that orders its items with less,
+// instead of requiring them to implement Lesser.
+func NewWith(less LessFunc) *LLRB {
+	if less == nil {
+		panic("nil less function")
+	}
+	return &LLRB{lessFunc: less, cow: new(cowToken)}
 }
 
 // SetRoot sets the root node of the tree.
 // It is intended to be used by functions that deserialize the tree.
+// The tree copies the nodes of r before it modifies them.
 func (t *LLRB) SetRoot(r *Node) {
+	t.mods++
 	t.root = r
+	if t.ranked || t.augment != nil {
+		t.root = t.updateAll(r)
+	}
 }
 
 // Root returns the root node of the tree.
@@ -90,6 +187,19 @@ func (t *LLRB) Root() *Node {
 	return t.root
 }
 
+// Clone returns a copy of t in O(1) time. Both trees remain mutable. They share
+// their nodes until either one modifies them, at which point that tree copies the
+// nodes on the path it modifies, leaving the other tree unaffected.
+//
+// Clone only reads t, so several goroutines holding a read lock on t may clone
+// it at once.
+func (t *LLRB) Clone() *LLRB {
+	t.share()
+	c := *t
+	c.cow = new(cowToken)
+	return &c
+}
+
 // Len returns the number of nodes in the tree.
 func (t *LLRB) Len() int { return t.count }
 
@@ -102,10 +212,10 @@ func (t *LLRB) Has(key Item) bool {
 func (t *LLRB) Get(key Item) Item {
 	h := t.root
 	for h != nil {
-		switch {
-		case less(key, h.Item):
+		switch c := t.compare(key, h.Item); {
+		case c < 0:
 			h = h.Left
-		case less(h.Item, key):
+		case c > 0:
 			h = h.Right
 		default:
 			return h.Item
@@ -138,6 +248,66 @@ func (t *LLRB) Max() Item {
 	return h.Item
 }
 
+// Floor returns the greatest element in the tree that is less than or equal to key,
+// or nil if there is none.
+func (t *LLRB) Floor(key Item) Item {
+	var floor Item
+	h := t.root
+	for h != nil {
+		if t.less(key, h.Item) {
+			h = h.Left
+		} else {
+			floor, h = h.Item, h.Right
+		}
+	}
+	return floor
+}
+
+// Ceiling returns the least element in the tree that is greater than or equal to key,
+// or nil if there is none.
+func (t *LLRB) Ceiling(key Item) Item {
+	var ceiling Item
+	h := t.root
+	for h != nil {
+		if t.less(h.Item, key) {
+			h = h.Right
+		} else {
+			ceiling, h = h.Item, h.Left
+		}
+	}
+	return ceiling
+}
+
+// Lower returns the greatest element in the tree that is strictly less than key,
+// or nil if there is none.
+func (t *LLRB) Lower(key Item) Item {
+	var lower Item
+	h := t.root
+	for h != nil {
+		if t.less(h.Item, key) {
+			lower, h = h.Item, h.Right
+		} else {
+			h = h.Left
+		}
+	}
+	return lower
+}
+
+// Higher returns the least element in the tree that is strictly greater than key,
+// or nil if there is none.
+func (t *LLRB) Higher(key Item) Item {
+	var higher Item
+	h := t.root
+	for h != nil {
+		if t.less(key, h.Item) {
+			higher, h = h.Item, h.Left
+		} else {
+			h = h.Right
+		}
+	}
+	return higher
+}
+
 func (t *LLRB) ReplaceOrInsertBulk(items ...Item) {
 	for _, i := range items {
 		t.ReplaceOrInsert(i)
@@ -156,6 +326,7 @@ func (t *LLRB) ReplaceOrInsert(item Item) Item {
 	if item == nil {
 		panic("inserting nil item")
 	}
+	t.mods++
 	var replaced Item
 	t.root, replaced = t.replaceOrInsert(t.root, item)
 	t.root.Black = true
@@ -167,21 +338,23 @@ func (t *LLRB) ReplaceOrInsert(item Item) Item {
 
 func (t *LLRB) replaceOrInsert(h *Node, item Item) (*Node, Item) {
 	if h == nil {
-		return newNode(item), nil
+		return t.newNode(item), nil
 	}
 
-	h = walkDownRot23(h)
+	h = t.mutable(h)
+	h = t.walkDownRot23(h)
 
 	var replaced Item
-	if less(item, h.Item) { // BUG
+	switch c := t.compare(item, h.Item); {
+	case c < 0:
 		h.Left, replaced = t.replaceOrInsert(h.Left, item)
-	} else if less(h.Item, item) {
+	case c > 0:
 		h.Right, replaced = t.replaceOrInsert(h.Right, item)
-	} else {
+	default:
 		replaced, h.Item = h.Item, item
 	}
 
-	h = walkUpRot23(h)
+	h = t.walkUpRot23(h)
 
 	return h, replaced
 }
@@ -192,6 +365,7 @@ func (t *LLRB) InsertNoReplace(item Item) {
 	if item == nil {
 		panic("inserting nil item")
 	}
+	t.mods++
 	t.root = t.insertNoReplace(t.root, item)
 	t.root.Black = true
 	t.count++
@@ -199,35 +373,38 @@ func (t *LLRB) InsertNoReplace(item Item) {
 
 func (t *LLRB) insertNoReplace(h *Node, item Item) *Node {
 	if h == nil {
-		return newNode(item)
+		return t.newNode(item)
 	}
 
-	h = walkDownRot23(h)
+	h = t.mutable(h)
+	h = t.walkDownRot23(h)
 
-	if less(item, h.Item) {
+	if t.less(item, h.Item) {
 		h.Left = t.insertNoReplace(h.Left, item)
 	} else {
 		h.Right = t.insertNoReplace(h.Right, item)
 	}
 
-	return walkUpRot23(h)
+	return t.walkUpRot23(h)
 }
 
 // Rotation driver routines for 2-3 algorithm
 
-func walkDownRot23(h *Node) *Node { return h }
+func (t *LLRB) walkDownRot23(h *Node) *Node { return h }
+
+func (t *LLRB) walkUpRot23(h *Node) *Node {
+	t.update(h)
 
-func walkUpRot23(h *Node) *Node {
 	if isRed(h.Right) && !isRed(h.Left) {
-		h = rotateLeft(h)
+		h = t.rotateLeft(h)
 	}
 
 	if isRed(h.Left) && isRed(h.Left.Left) {
-		h = rotateRight(h)
+		h = t.rotateRight(h)
 	}
 
 	if isRed(h.Left) && isRed(h.Right) {
-		flip(h)
+		t.flip(h)
 	}
 
 	return h
@@ -235,21 +412,23 @@ func walkUpRot23(h *Node) *Node {
 
 // Rotation driver routines for 2-3-4 algorithm
 
-func walkDownRot234(h *Node) *Node {
+func (t *LLRB) walkDownRot234(h *Node) *Node {
 	if isRed(h.Left) && isRed(h.Right) {
-		flip(h)
+		t.flip(h)
 	}
 
 	return h
 }
 
-func walkUpRot234(h *Node) *Node {
+func (t *LLRB) walkUpRot234(h *Node) *Node {
+	t.update(h)
+
 	if isRed(h.Right) && !isRed(h.Left) {
-		h = rotateLeft(h)
+		h = t.rotateLeft(h)
 	}
 
 	if isRed(h.Left) && isRed(h.Left.Left) {
-		h = rotateRight(h)
+		h = t.rotateRight(h)
 	}
 
 	return h
@@ -258,8 +437,9 @@ func walkUpRot234(h *Node) *Node {
 // DeleteMin deletes the minimum element in the tree and returns the
 // deleted item or nil otherwise.
 func (t *LLRB) DeleteMin() Item {
+	t.mods++
 	var deleted Item
-	t.root, deleted = deleteMin(t.root)
+	t.root, deleted = t.deleteMin(t.root)
 	if t.root != nil {
 		t.root.Black = true
 	}
@@ -270,29 +450,31 @@ func (t *LLRB) DeleteMin() Item {
 }
 
 // deleteMin code for LLRB 2-3 trees
-func deleteMin(h *Node) (*Node, Item) {
+func (t *LLRB) deleteMin(h *Node) (*Node, Item) {
 	if h == nil {
 		return nil, nil
 	}
 	if h.Left == nil {
 		return nil, h.Item
 	}
+	h = t.mutable(h)
 
 	if !isRed(h.Left) && !isRed(h.Left.Left) {
-		h = moveRedLeft(h)
+		h = t.moveRedLeft(h)
 	}
 
 	var deleted Item
-	h.Left, deleted = deleteMin(h.Left)
+	h.Left, deleted = t.deleteMin(h.Left)
 
-	return fixUp(h), deleted
+	return t.fixUp(h), deleted
 }
 
 // DeleteMax deletes the maximum element in the tree and returns
 // the deleted item or nil otherwise
 func (t *LLRB) DeleteMax() Item {
+	t.mods++
 	var deleted Item
-	t.root, deleted = deleteMax(t.root)
+	t.root, deleted = t.deleteMax(t.root)
 	if t.root != nil {
 		t.root.Black = true
 	}
@@ -302,28 +484,30 @@ func (t *LLRB) DeleteMax() Item {
 	return deleted
 }
 
-func deleteMax(h *Node) (*Node, Item) {
+func (t *LLRB) deleteMax(h *Node) (*Node, Item) {
 	if h == nil {
 		return nil, nil
 	}
+	h = t.mutable(h)
 	if isRed(h.Left) {
-		h = rotateRight(h)
+		h = t.rotateRight(h)
 	}
 	if h.Right == nil {
 		return nil, h.Item
 	}
 	if !isRed(h.Right) && !isRed(h.Right.Left) {
-		h = moveRedRight(h)
+		h = t.moveRedRight(h)
 	}
 	var deleted Item
-	h.Right, deleted = deleteMax(h.Right)
+	h.Right, deleted = t.deleteMax(h.Right)
 
-	return fixUp(h), deleted
+	return t.fixUp(h), deleted
 }
 
 // Delete deletes an item from the tree whose key equals key.
 // The deleted item is return, otherwise nil is returned.
 func (t *LLRB) Delete(key Item) Item {
+	t.mods++ // The search restructures the tree even if key is absent
 	var deleted Item
 	t.root, deleted = t.delete(t.root, key)
 	if t.root != nil {
@@ -340,30 +524,39 @@ func (t *LLRB) delete(h *Node, item Item) (*Node, Item) {
 	if h == nil {
 		return nil, nil
 	}
-	if less(item, h.Item) {
+	h = t.mutable(h)
+	c := t.compare(item, h.Item)
+	if c < 0 {
 		if h.Left == nil { // item not present. Nothing to delete
 			return h, nil
 		}
 		if !isRed(h.Left) && !isRed(h.Left.Left) {
-			h = moveRedLeft(h)
+			h = t.moveRedLeft(h)
 		}
 		h.Left, deleted = t.delete(h.Left, item)
 	} else {
+		// Rotations change @h, after which @c must be recomputed
 		if isRed(h.Left) {
-			h = rotateRight(h)
+			h = t.rotateRight(h)
+			c = t.compare(item, h.Item)
 		}
 		// If @item equals @h.Item and no right children at @h
-		if !less(h.Item, item) && h.Right == nil {
+		if c <= 0 && h.Right == nil {
 			return nil, h.Item
 		}
 		// PETAR: Added 'h.Right != nil' below
 		if h.Right != nil && !isRed(h.Right) && !isRed(h.Right.Left) {
-			h = moveRedRight(h)
+			if x := t.moveRedRight(h); x != h {
+				// The rotation moved the old @h, which may itself equal @item,
+				// into the right subtree. Continue the search there, since
+				// only that subtree is prepared for a deletion.
+				h, c = x, 1
+			}
 		}
 		// If @item equals @h.Item, and (from above) 'h.Right != nil'
-		if !less(h.Item, item) {
+		if c <= 0 {
 			var subDeleted Item
-			h.Right, subDeleted = deleteMin(h.Right)
+			h.Right, subDeleted = t.deleteMin(h.Right)
 			if subDeleted == nil {
 				panic("logic")
 			}
@@ -373,12 +566,60 @@ func (t *LLRB) delete(h *Node, item Item) (*Node, Item) {
 		}
 	}
 
-	return fixUp(h), deleted
+	return t.fixUp(h), deleted
 }
 
 // Internal node manipulation routines
 
-func newNode(item Item) *Node { return &Node{Item: item} }
+func (t *LLRB) newNode(item Item) *Node {
+	h := &Node{Item: item, cow: t.token()}
+	t.update(h)
+	return h
+}
+
+// mutable returns h if t owns it, and otherwise a copy of h that t owns.
+// Every node is passed through mutable before it is modified.
+func (t *LLRB) mutable(h *Node) *Node {
+	if h == nil || h.cow == t.token() {
+		return h
+	}
+	c := *h
+	c.cow = t.cow
+	if h.aug != nil {
+		aug := *h.aug
+		c.aug = &aug
+	}
+	return &c
+}
+
+// update recomputes the augmented fields of h from those of its children.
+// It must be called whenever the children of h change.
+func (t *LLRB) update(h *Node) {
+	if !t.ranked && t.augment == nil {
+		return
+	}
+	if h.aug == nil {
+		h.aug = new(nodeAug)
+	}
+	if t.ranked {
+		h.aug.size = 1 + size(h.Left) + size(h.Right)
+	}
+	if t.augment != nil {
+		h.aug.summary = t.augment(h.Item, summary(h.Left), summary(h.Right))
+	}
+}
+
+// updateAll recomputes the augmented fields of every node in the subtree at h.
+func (t *LLRB) updateAll(h *Node) *Node {
+	if h == nil {
+		return nil
+	}
+	h = t.mutable(h)
+	h.Left = t.updateAll(h.Left)
+	h.Right = t.updateAll(h.Right)
+	t.update(h)
+	return h
+}
 
 func isRed(h *Node) bool {
 	if h == nil {
@@ -387,8 +628,8 @@ func isRed(h *Node) bool {
 	return !h.Black
 }
 
-func rotateLeft(h *Node) *Node {
-	x := h.Right
+func (t *LLRB) rotateLeft(h *Node) *Node {
+	x := t.mutable(h.Right)
 	if x.Black {
 		panic("rotating a black link")
 	}
@@ -396,11 +637,13 @@ func rotateLeft(h *Node) *Node {
 	x.Left = h
 	x.Black = h.Black
 	h.Black = false
+	t.update(h)
+	t.update(x)
 	return x
 }
 
-func rotateRight(h *Node) *Node {
-	x := h.Left
+func (t *LLRB) rotateRight(h *Node) *Node {
+	x := t.mutable(h.Left)
 	if x.Black {
 		panic("rotating a black link")
 	}
@@ -408,48 +651,53 @@ func rotateRight(h *Node) *Node {
 	x.Right = h
 	x.Black = h.Black
 	h.Black = false
+	t.update(h)
+	t.update(x)
 	return x
 }
 
 // REQUIRE: Left and Right children must be present
-func flip(h *Node) {
+func (t *LLRB) flip(h *Node) {
+	h.Left, h.Right = t.mutable(h.Left), t.mutable(h.Right)
 	h.Black = !h.Black
 	h.Left.Black = !h.Left.Black
 	h.Right.Black = !h.Right.Black
 }
 
 // REQUIRE: Left and Right children must be present
-func moveRedLeft(h *Node) *Node {
-	flip(h)
+func (t *LLRB) moveRedLeft(h *Node) *Node {
+	t.flip(h)
 	if isRed(h.Right.Left) {
-		h.Right = rotateRight(h.Right)
-		h = rotateLeft(h)
-		flip(h)
+		h.Right = t.rotateRight(h.Right)
+		h = t.rotateLeft(h)
+		t.flip(h)
 	}
 	return h
 }
 
 // REQUIRE: Left and Right children must be present
-func moveRedRight(h *Node) *Node {
-	flip(h)
+func (t *LLRB) moveRedRight(h *Node) *Node {
+	t.flip(h)
 	if isRed(h.Left.Left) {
-		h = rotateRight(h)
-		flip(h)
+		h = t.rotateRight(h)
+		t.flip(h)
 	}
 	return h
 }
 
-func fixUp(h *Node) *Node {
+func (t *LLRB) fixUp(h *Node) *Node {
+	t.update(h)
+
 	if isRed(h.Right) {
-		h = rotateLeft(h)
+		h = t.rotateLeft(h)
 	}
 
 	if isRed(h.Left) && isRed(h.Left.Left) {
-		h = rotateRight(h)
+		h = t.rotateRight(h)
 	}
 
 	if isRed(h.Left) && isRed(h.Right) {
-		flip(h)
+		t.flip(h)
 	}
 
 	return h
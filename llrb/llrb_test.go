@@ -5,11 +5,22 @@
 package llrb
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"testing"
 )
 
+// collect returns the items visited by walk, in order.
+func collect(walk func(ItemIterator)) []Item {
+	var items []Item
+	walk(func(i Item) bool {
+		items = append(items, i)
+		return true
+	})
+	return items
+}
+
 func TestCases(t *testing.T) {
 	tree := New()
 	tree.ReplaceOrInsert(Int(1))
@@ -78,114 +89,120 @@ func TestRange(t *testing.T) {
 }
 
 func TestRandomInsertOrder(t *testing.T) {
-	tree := New()
-	n := 1000
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	j := 0
-	tree.AscendGreaterOrEqual(Int(0), func(item Item) bool {
-		if item.(Int) != Int(j) {
-			t.Fatalf("bad order")
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 1000
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
 		}
-		j++
-		return true
+		j := 0
+		tree.AscendGreaterOrEqual(Int(0), func(item Item) bool {
+			if item.(Int) != Int(j) {
+				t.Fatalf("bad order")
+			}
+			j++
+			return true
+		})
 	})
 }
 
 func TestRandomReplace(t *testing.T) {
-	tree := New()
-	n := 100
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	perm = rand.Perm(n)
-	for i := 0; i < n; i++ {
-		if replaced := tree.ReplaceOrInsert(Int(perm[i])); replaced == nil || replaced.(Int) != Int(perm[i]) {
-			t.Errorf("error replacing")
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 100
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
 		}
-	}
+		perm = rand.Perm(n)
+		for i := 0; i < n; i++ {
+			if replaced := tree.ReplaceOrInsert(Int(perm[i])); replaced == nil || replaced.(Int) != Int(perm[i]) {
+				t.Errorf("error replacing")
+			}
+		}
+	})
 }
 
 func TestRandomInsertSequentialDelete(t *testing.T) {
-	tree := New()
-	n := 1000
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	for i := 0; i < n; i++ {
-		tree.Delete(Int(i))
-	}
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 1000
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
+		}
+		for i := 0; i < n; i++ {
+			tree.Delete(Int(i))
+		}
+	})
 }
 
 func TestRandomInsertDeleteNonExistent(t *testing.T) {
-	tree := New()
-	n := 100
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	if tree.Delete(Int(200)) != nil {
-		t.Errorf("deleted non-existent item")
-	}
-	if tree.Delete(Int(-2)) != nil {
-		t.Errorf("deleted non-existent item")
-	}
-	for i := 0; i < n; i++ {
-		if u := tree.Delete(Int(i)); u == nil || u.(Int) != Int(i) {
-			t.Errorf("delete failed")
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 100
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
 		}
-	}
-	if tree.Delete(Int(200)) != nil {
-		t.Errorf("deleted non-existent item")
-	}
-	if tree.Delete(Int(-2)) != nil {
-		t.Errorf("deleted non-existent item")
-	}
+		if tree.Delete(Int(200)) != nil {
+			t.Errorf("deleted non-existent item")
+		}
+		if tree.Delete(Int(-2)) != nil {
+			t.Errorf("deleted non-existent item")
+		}
+		for i := 0; i < n; i++ {
+			if u := tree.Delete(Int(i)); u == nil || u.(Int) != Int(i) {
+				t.Errorf("delete failed")
+			}
+		}
+		if tree.Delete(Int(200)) != nil {
+			t.Errorf("deleted non-existent item")
+		}
+		if tree.Delete(Int(-2)) != nil {
+			t.Errorf("deleted non-existent item")
+		}
+	})
 }
 
 func TestRandomInsertPartialDeleteOrder(t *testing.T) {
-	tree := New()
-	n := 100
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	for i := 1; i < n-1; i++ {
-		tree.Delete(Int(i))
-	}
-	j := 0
-	tree.AscendGreaterOrEqual(Int(0), func(item Item) bool {
-		switch j {
-		case 0:
-			if item.(Int) != Int(0) {
-				t.Errorf("expecting 0")
-			}
-		case 1:
-			if item.(Int) != Int(n-1) {
-				t.Errorf("expecting %d", n-1)
-			}
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 100
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
 		}
-		j++
-		return true
+		for i := 1; i < n-1; i++ {
+			tree.Delete(Int(i))
+		}
+		j := 0
+		tree.AscendGreaterOrEqual(Int(0), func(item Item) bool {
+			switch j {
+			case 0:
+				if item.(Int) != Int(0) {
+					t.Errorf("expecting 0")
+				}
+			case 1:
+				if item.(Int) != Int(n-1) {
+					t.Errorf("expecting %d", n-1)
+				}
+			}
+			j++
+			return true
+		})
 	})
 }
 
 func TestRandomInsertStats(t *testing.T) {
-	tree := New()
-	n := 100000
-	perm := rand.Perm(n)
-	for i := 0; i < n; i++ {
-		tree.ReplaceOrInsert(Int(perm[i]))
-	}
-	avg, _ := tree.HeightStats()
-	expAvg := math.Log2(float64(n)) - 1.5
-	if math.Abs(avg-expAvg) >= 2.0 {
-		t.Errorf("too much deviation from expected average height")
-	}
+	forEachTree(t, func(t *testing.T, tree testTree) {
+		n := 100000
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.ReplaceOrInsert(Int(perm[i]))
+		}
+		avg, _ := tree.HeightStats()
+		expAvg := math.Log2(float64(n)) - 1.5
+		if math.Abs(avg-expAvg) >= 2.0 {
+			t.Errorf("too much deviation from expected average height")
+		}
+	})
 }
 
 func BenchmarkInsert(b *testing.B) {
@@ -195,6 +212,57 @@ func BenchmarkInsert(b *testing.B) {
 	}
 }
 
+// lessString is a String without a Compare method
+type lessString string
+
+func (x lessString) Less(than Item) bool {
+	return x < than.(lessString)
+}
+
+const compositePrefix = "tenant/0000000000/region/us-east/bucket/000000/object/"
+
+func benchmarkGet(b *testing.B, key func(i int) Item) {
+	b.StopTimer()
+	tree := New()
+	n := 1 << 16
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(key(i))
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Get(key(i % n))
+	}
+}
+
+func BenchmarkGetLess(b *testing.B) {
+	benchmarkGet(b, func(i int) Item { return lessString(fmt.Sprintf("%s%08d", compositePrefix, i)) })
+}
+
+func BenchmarkGetCompare(b *testing.B) {
+	benchmarkGet(b, func(i int) Item { return String(fmt.Sprintf("%s%08d", compositePrefix, i)) })
+}
+
+func benchmarkInsert(b *testing.B, key func(i int) Item) {
+	b.StopTimer()
+	keys := make([]Item, b.N)
+	for i, j := range rand.Perm(b.N) {
+		keys[i] = key(j)
+	}
+	tree := New()
+	b.StartTimer()
+	for _, k := range keys {
+		tree.ReplaceOrInsert(k)
+	}
+}
+
+func BenchmarkInsertLess(b *testing.B) {
+	benchmarkInsert(b, func(i int) Item { return lessString(fmt.Sprintf("%s%08d", compositePrefix, i)) })
+}
+
+func BenchmarkInsertCompare(b *testing.B) {
+	benchmarkInsert(b, func(i int) Item { return String(fmt.Sprintf("%s%08d", compositePrefix, i)) })
+}
+
 func BenchmarkDelete(b *testing.B) {
 	b.StopTimer()
 	tree := New()
@@ -237,3 +305,188 @@ func TestInsertNoReplace(t *testing.T) {
 		return true
 	})
 }
+
+func lessInt(a, b interface{}) bool { return a.(int) < b.(int) }
+
+func TestNewWith(t *testing.T) {
+	tree := NewWith(lessInt)
+	n := 1000
+	perm := rand.Perm(n)
+	for i := 0; i < n; i++ {
+		tree.ReplaceOrInsert(perm[i])
+	}
+	if tree.Len() != n {
+		t.Errorf("expecting len %d, got %d", n, tree.Len())
+	}
+	if !tree.Has(17) || tree.Has(n) {
+		t.Errorf("bad membership")
+	}
+	j := 0
+	tree.AscendRange(Inf(-1), Inf(1), func(item Item) bool {
+		if item.(int) != j {
+			t.Fatalf("bad order")
+		}
+		j++
+		return true
+	})
+	if j != n {
+		t.Errorf("expecting %d items, got %d", n, j)
+	}
+	for i := 0; i < n; i += 2 {
+		if u := tree.Delete(i); u == nil || u.(int) != i {
+			t.Errorf("delete failed")
+		}
+	}
+	if tree.Min().(int) != 1 || tree.Max().(int) != n-1 {
+		t.Errorf("bad min or max")
+	}
+}
+
+// countingInt counts the calls made to its Less and Compare methods
+type countingInt struct {
+	v              int
+	less, compares *int
+}
+
+func (x countingInt) Less(than Item) bool {
+	*x.less++
+	return x.v < than.(countingInt).v
+}
+
+func (x countingInt) Compare(than Item) int {
+	*x.compares++
+	return x.v - than.(countingInt).v
+}
+
+func TestComparer(t *testing.T) {
+	var less, compares int
+	item := func(v int) countingInt { return countingInt{v, &less, &compares} }
+	tree := New()
+	n := 1000
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(item(i))
+	}
+	for _, i := range rand.Perm(n) {
+		if i%2 == 0 {
+			tree.Delete(item(i))
+		}
+	}
+	less, compares = 0, 0
+	for i := 0; i < n; i++ {
+		if tree.Has(item(i)) != (i%2 == 1) {
+			t.Fatalf("bad membership for %d", i)
+		}
+		if result, _ := tree.GetHeight(item(i)); (result != nil) != (i%2 == 1) {
+			t.Fatalf("bad height lookup for %d", i)
+		}
+	}
+	if less != 0 || compares == 0 {
+		t.Errorf("expecting lookups to use Compare only, got %d Less and %d Compare calls", less, compares)
+	}
+	if tree.Len() != n/2 {
+		t.Errorf("expecting len %d, got %d", n/2, tree.Len())
+	}
+}
+
+// checkLLRB verifies the order, color and balance invariants of tree, and its count.
+func checkLLRB(t *testing.T, tree *LLRB) {
+	t.Helper()
+	if isRed(tree.root) {
+		t.Fatalf("red root")
+	}
+	n, _ := checkNode(t, tree, tree.root, nil, nil)
+	if n != tree.Len() {
+		t.Fatalf("expecting %d nodes, tree has %d", tree.Len(), n)
+	}
+}
+
+// checkNode returns the size and black height of the subtree at h,
+// whose items must lie within [lo, hi] when those are not nil.
+func checkNode(t *testing.T, tree *LLRB, h *Node, lo, hi Item) (n, bh int) {
+	if h == nil {
+		return 0, 0
+	}
+	if (lo != nil && tree.less(h.Item, lo)) || (hi != nil && tree.less(hi, h.Item)) {
+		t.Fatalf("item %v out of order", h.Item)
+	}
+	if isRed(h.Right) {
+		t.Fatalf("red right link at %v", h.Item)
+	}
+	if isRed(h) && isRed(h.Left) {
+		t.Fatalf("two red links in a row at %v", h.Item)
+	}
+	ls, lbh := checkNode(t, tree, h.Left, lo, h.Item)
+	rs, rbh := checkNode(t, tree, h.Right, h.Item, hi)
+	if lbh != rbh {
+		t.Fatalf("unbalanced at %v: black heights %d and %d", h.Item, lbh, rbh)
+	}
+	if tree.ranked && size(h) != ls+rs+1 {
+		t.Fatalf("bad size at %v: expecting %d, got %d", h.Item, ls+rs+1, size(h))
+	}
+	if h.Black {
+		lbh++
+	}
+	return ls + rs + 1, lbh
+}
+
+func TestFloorCeiling(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(50) {
+		tree.ReplaceOrInsert(Int(2 * i)) // the even numbers 0..98
+	}
+	for k := -2; k <= 100; k++ {
+		var floor, ceiling, lower, higher Item
+		for i := 0; i < 100; i += 2 {
+			if i <= k {
+				floor = Int(i)
+			}
+			if i < k {
+				lower = Int(i)
+			}
+			if i >= k && ceiling == nil {
+				ceiling = Int(i)
+			}
+			if i > k && higher == nil {
+				higher = Int(i)
+			}
+		}
+		if got := tree.Floor(Int(k)); got != floor {
+			t.Errorf("floor of %d: expecting %v, got %v", k, floor, got)
+		}
+		if got := tree.Ceiling(Int(k)); got != ceiling {
+			t.Errorf("ceiling of %d: expecting %v, got %v", k, ceiling, got)
+		}
+		if got := tree.Lower(Int(k)); got != lower {
+			t.Errorf("lower of %d: expecting %v, got %v", k, lower, got)
+		}
+		if got := tree.Higher(Int(k)); got != higher {
+			t.Errorf("higher of %d: expecting %v, got %v", k, higher, got)
+		}
+	}
+}
+
+func TestFloorCeilingInf(t *testing.T) {
+	tree := New()
+	if tree.Floor(Inf(1)) != nil || tree.Ceiling(Inf(-1)) != nil {
+		t.Errorf("expecting nil from an empty tree")
+	}
+	tree.ReplaceOrInsertBulk(Int(3), Int(1), Int(2))
+	cases := []struct {
+		name          string
+		got, expected Item
+	}{
+		{"Floor(+inf)", tree.Floor(Inf(1)), Int(3)},
+		{"Floor(-inf)", tree.Floor(Inf(-1)), nil},
+		{"Ceiling(-inf)", tree.Ceiling(Inf(-1)), Int(1)},
+		{"Ceiling(+inf)", tree.Ceiling(Inf(1)), nil},
+		{"Lower(+inf)", tree.Lower(Inf(1)), Int(3)},
+		{"Lower(-inf)", tree.Lower(Inf(-1)), nil},
+		{"Higher(-inf)", tree.Higher(Inf(-1)), Int(1)},
+		{"Higher(+inf)", tree.Higher(Inf(1)), nil},
+	}
+	for _, c := range cases {
+		if c.got != c.expected {
+			t.Errorf("%s: expecting %v, got %v", c.name, c.expected, c.got)
+		}
+	}
+}
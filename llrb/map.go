@@ -0,0 +1,135 @@
+package llrb
+
+// Map is an ordered map from keys of type K to values of type V.
+// It is an LLRB whose items are key/value pairs ordered by key.
+type Map[K, V any] struct {
+	tree *LLRB
+}
+
+// MapIterator is called once for each visited pair. Returning false stops the traversal.
+type MapIterator[K, V any] func(key K, value V) bool
+
+type mapEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+// NewMap allocates a new map whose keys are ordered by less.
+func NewMap[K, V any](less func(a, b K) bool) *Map[K, V] {
+	if less == nil {
+		panic("nil less function")
+	}
+	return &Map[K, V]{
+		tree: NewWith(func(a, b interface{}) bool {
+			return less(a.(mapEntry[K, V]).key, b.(mapEntry[K, V]).key)
+		}),
+	}
+}
+
+// Len returns the number of pairs in the map.
+func (m *Map[K, V]) Len() int { return m.tree.Len() }
+
+// Has returns true if the map contains key.
+func (m *Map[K, V]) Has(key K) bool {
+	return m.tree.Has(mapEntry[K, V]{key: key})
+}
+
+// Get returns the value stored under key. The boolean result is false if key is absent.
+func (m *Map[K, V]) Get(key K) (value V, ok bool) {
+	return m.unpack(m.tree.Get(mapEntry[K, V]{key: key}))
+}
+
+// Set stores value under key, replacing any previous value.
+func (m *Map[K, V]) Set(key K, value V) {
+	m.tree.ReplaceOrInsert(mapEntry[K, V]{key, value})
+}
+
+// Delete removes key from the map and returns the value stored under it.
+// The boolean result is false if key was absent.
+func (m *Map[K, V]) Delete(key K) (value V, ok bool) {
+	return m.unpack(m.tree.Delete(mapEntry[K, V]{key: key}))
+}
+
+// Min returns the pair with the smallest key.
+func (m *Map[K, V]) Min() (key K, value V, ok bool) {
+	return m.unpackPair(m.tree.Min())
+}
+
+// Max returns the pair with the largest key.
+func (m *Map[K, V]) Max() (key K, value V, ok bool) {
+	return m.unpackPair(m.tree.Max())
+}
+
+// DeleteMin removes and returns the pair with the smallest key.
+func (m *Map[K, V]) DeleteMin() (key K, value V, ok bool) {
+	return m.unpackPair(m.tree.DeleteMin())
+}
+
+// DeleteMax removes and returns the pair with the largest key.
+func (m *Map[K, V]) DeleteMax() (key K, value V, ok bool) {
+	return m.unpackPair(m.tree.DeleteMax())
+}
+
+func (m *Map[K, V]) unpack(i Item) (value V, ok bool) {
+	_, value, ok = m.unpackPair(i)
+	return
+}
+
+func (m *Map[K, V]) unpackPair(i Item) (key K, value V, ok bool) {
+	if i == nil {
+		return
+	}
+	e := i.(mapEntry[K, V])
+	return e.key, e.value, true
+}
+
+func (m *Map[K, V]) iterator(iterator MapIterator[K, V]) ItemIterator {
+	return func(i Item) bool {
+		e := i.(mapEntry[K, V])
+		return iterator(e.key, e.value)
+	}
+}
+
+// Ascend will call iterator once for each pair in ascending key order.
+// It will stop whenever the iterator returns false.
+func (m *Map[K, V]) Ascend(iterator MapIterator[K, V]) {
+	m.tree.Ascend(m.iterator(iterator))
+}
+
+// Descend will call iterator once for each pair in descending key order.
+// It will stop whenever the iterator returns false.
+func (m *Map[K, V]) Descend(iterator MapIterator[K, V]) {
+	m.tree.Descend(m.iterator(iterator))
+}
+
+// AscendRange will call iterator once for each pair whose key is greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (m *Map[K, V]) AscendRange(greaterOrEqual, lessThan K, iterator MapIterator[K, V]) {
+	m.tree.AscendRange(mapEntry[K, V]{key: greaterOrEqual}, mapEntry[K, V]{key: lessThan}, m.iterator(iterator))
+}
+
+// AscendGreaterOrEqual will call iterator once for each pair whose key is greater or
+// equal to pivot, in ascending order. It will stop whenever the iterator returns false.
+func (m *Map[K, V]) AscendGreaterOrEqual(pivot K, iterator MapIterator[K, V]) {
+	m.tree.AscendGreaterOrEqual(mapEntry[K, V]{key: pivot}, m.iterator(iterator))
+}
+
+// AscendLessThan will call iterator once for each pair whose key is less than
+// pivot, in ascending order. It will stop whenever the iterator returns false.
+func (m *Map[K, V]) AscendLessThan(pivot K, iterator MapIterator[K, V]) {
+	m.tree.AscendLessThan(mapEntry[K, V]{key: pivot}, m.iterator(iterator))
+}
+
+// DescendRange will call iterator once for each pair whose key is less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (m *Map[K, V]) DescendRange(lessOrEqual, greaterThan K, iterator MapIterator[K, V]) {
+	m.tree.DescendRange(mapEntry[K, V]{key: lessOrEqual}, mapEntry[K, V]{key: greaterThan}, m.iterator(iterator))
+}
+
+// DescendLessOrEqual will call iterator once for each pair whose key is less or
+// equal to pivot, in descending order. It will stop whenever the iterator returns false.
+func (m *Map[K, V]) DescendLessOrEqual(pivot K, iterator MapIterator[K, V]) {
+	m.tree.DescendLessOrEqual(mapEntry[K, V]{key: pivot}, m.iterator(iterator))
+}
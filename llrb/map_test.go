@@ -0,0 +1,88 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	m := NewMap[int, string](func(a, b int) bool { return a < b })
+	n := 1000
+	for _, i := range rand.Perm(n) {
+		m.Set(i, strconv.Itoa(i))
+	}
+	m.Set(7, "seven")
+	if m.Len() != n {
+		t.Errorf("expecting len %d, got %d", n, m.Len())
+	}
+	if v, ok := m.Get(7); !ok || v != "seven" {
+		t.Errorf("expecting replaced value, got %q", v)
+	}
+	if _, ok := m.Get(n); ok {
+		t.Errorf("found non-existent key")
+	}
+	if k, v, ok := m.Min(); !ok || k != 0 || v != "0" {
+		t.Errorf("bad min %d %q", k, v)
+	}
+	if k, v, ok := m.Max(); !ok || k != n-1 || v != strconv.Itoa(n-1) {
+		t.Errorf("bad max %d %q", k, v)
+	}
+	for i := 0; i < n; i += 2 {
+		if v, ok := m.Delete(i); !ok || (i != 7 && v != strconv.Itoa(i)) {
+			t.Errorf("delete failed for %d", i)
+		}
+	}
+	if _, ok := m.Delete(0); ok {
+		t.Errorf("deleted non-existent key")
+	}
+	j := 1
+	m.Ascend(func(k int, v string) bool {
+		if k != j || (k != 7 && v != strconv.Itoa(k)) {
+			t.Fatalf("bad pair %d %q", k, v)
+		}
+		j += 2
+		return true
+	})
+	if m.Len() != n/2 || j != n+1 {
+		t.Errorf("expecting len %d, got %d", n/2, m.Len())
+	}
+}
+
+func TestMapRanges(t *testing.T) {
+	m := NewMap[string, int](func(a, b string) bool { return a < b })
+	for i, k := range []string{"d", "a", "c", "e", "b"} {
+		m.Set(k, i)
+	}
+	var keys []string
+	collect := func(k string, v int) bool {
+		keys = append(keys, k)
+		return true
+	}
+	cases := []struct {
+		walk     func()
+		expected []string
+	}{
+		{func() { m.Ascend(collect) }, []string{"a", "b", "c", "d", "e"}},
+		{func() { m.Descend(collect) }, []string{"e", "d", "c", "b", "a"}},
+		{func() { m.AscendRange("b", "d", collect) }, []string{"b", "c"}},
+		{func() { m.AscendGreaterOrEqual("bb", collect) }, []string{"c", "d", "e"}},
+		{func() { m.AscendLessThan("c", collect) }, []string{"a", "b"}},
+		{func() { m.DescendRange("d", "b", collect) }, []string{"d", "c"}},
+		{func() { m.DescendLessOrEqual("cc", collect) }, []string{"c", "b", "a"}},
+	}
+	for i, c := range cases {
+		keys = nil
+		c.walk()
+		if !reflect.DeepEqual(keys, c.expected) {
+			t.Errorf("case %d: expected %v but got %v", i, c.expected, keys)
+		}
+	}
+	if k, v, ok := m.DeleteMin(); !ok || k != "a" || v != 1 {
+		t.Errorf("bad DeleteMin %q %d", k, v)
+	}
+	if k, v, ok := m.DeleteMax(); !ok || k != "e" || v != 3 {
+		t.Errorf("bad DeleteMax %q %d", k, v)
+	}
+}
@@ -0,0 +1,124 @@
+package llrb
+
+// The methods in this file treat the tree as a multiset, in which InsertNoReplace
+// may have stored several items of the same order. InsertNoReplace places a new
+// item after all items of the same order, and no operation reorders items, so
+// equal items are always visited in the order in which they were inserted.
+
+// Count returns the number of items in the tree whose order is the same as that of key.
+func (t *LLRB) Count(key Item) int {
+	n := 0
+	t.AscendEqual(key, func(Item) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// GetAll returns the items in the tree whose order is the same as that of key,
+// in insertion order.
+func (t *LLRB) GetAll(key Item) []Item {
+	var items []Item
+	t.AscendEqual(key, func(i Item) bool {
+		items = append(items, i)
+		return true
+	})
+	return items
+}
+
+// AscendEqual will call iterator once for each item whose order is the same as that
+// of key, in insertion order. It will stop whenever the iterator returns false.
+func (t *LLRB) AscendEqual(key Item, iterator ItemIterator) {
+	t.ascendEqual(t.root, key, t.guard(iterator))
+}
+
+func (t *LLRB) ascendEqual(h *Node, key Item, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	switch c := t.compare(key, h.Item); {
+	case c < 0:
+		return t.ascendEqual(h.Left, key, iterator)
+	case c > 0:
+		return t.ascendEqual(h.Right, key, iterator)
+	}
+	if !t.ascendEqual(h.Left, key, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.ascendEqual(h.Right, key, iterator)
+}
+
+// DeleteAll deletes all items whose order is the same as that of key, and returns
+// how many were deleted. It takes O(log n + k) time to delete k items, or O(log n)
+// time in a tree with EnableRank.
+func (t *LLRB) DeleteAll(key Item) int {
+	if !t.Has(key) {
+		return 0
+	}
+	t.mods++
+	l, lbh, r, rbh := t.split(t.root, blackHeight(t.root), key)
+	m, _, r, rbh := t.splitBy(r, rbh, func(h *Node) bool { return !t.less(key, h.Item) })
+	n := 0
+	if t.ranked {
+		n = size(m)
+	} else {
+		n = countNodes(m)
+	}
+	t.root, _ = t.concat(l, lbh, r, rbh)
+	t.count -= n
+	return n
+}
+
+// DeleteExact deletes the first item, in insertion order, whose order is the same
+// as that of item and for which eq(item, candidate) is true. The deleted item is
+// returned, otherwise nil is returned. The other items of the same order keep
+// their relative order.
+func (t *LLRB) DeleteExact(item Item, eq func(a, b Item) bool) Item {
+	path := t.pathToExact(t.root, item, eq, nil)
+	if path == nil {
+		return nil
+	}
+	// Split the tree just before the node at the end of path, which is then the
+	// minimum of the right part. Splitting follows path, so the nodes on it tell
+	// which side they go to, and the others all precede the deleted node.
+	before := make(map[*Node]bool, len(path))
+	for i := 0; i+1 < len(path); i++ {
+		before[path[i]] = path[i+1] == path[i].Right
+	}
+	before[path[len(path)-1]] = false
+	t.mods++
+	l, lbh, r, _ := t.splitBy(t.root, blackHeight(t.root), func(h *Node) bool {
+		b, on := before[h]
+		return b || !on
+	})
+	r, deleted := t.deleteMin(r)
+	r, _ = t.blacken(r, 0)
+	t.root, _ = t.concat(l, lbh, r, blackHeight(r))
+	t.count--
+	return deleted
+}
+
+// pathToExact returns the path from h down to the node holding the first item, in
+// order, that DeleteExact would delete, or nil if there is no such item.
+func (t *LLRB) pathToExact(h *Node, item Item, eq func(a, b Item) bool, path []*Node) []*Node {
+	if h == nil {
+		return nil
+	}
+	path = append(path, h)
+	switch c := t.compare(item, h.Item); {
+	case c < 0:
+		return t.pathToExact(h.Left, item, eq, path)
+	case c > 0:
+		return t.pathToExact(h.Right, item, eq, path)
+	}
+	if p := t.pathToExact(h.Left, item, eq, path); p != nil {
+		return p
+	}
+	if eq(item, h.Item) {
+		return path
+	}
+	return t.pathToExact(h.Right, item, eq, path)
+}
@@ -0,0 +1,175 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// seqItem orders by key only, so items with different seq can be equal
+type seqItem struct {
+	key, seq int
+}
+
+func (x seqItem) Less(than Item) bool {
+	return x.key < than.(seqItem).key
+}
+
+func sameSeq(a, b Item) bool { return a.(seqItem).seq == b.(seqItem).seq }
+
+func newMultiset(n, copies int) *LLRB {
+	tree := New()
+	for c := 0; c < copies; c++ {
+		for _, i := range rand.Perm(n) {
+			tree.InsertNoReplace(seqItem{i, c})
+		}
+	}
+	return tree
+}
+
+func TestMultisetFIFO(t *testing.T) {
+	n, copies := 200, 5
+	tree := newMultiset(n, copies)
+	for i := 0; i < n; i++ {
+		if c := tree.Count(seqItem{key: i}); c != copies {
+			t.Fatalf("expecting %d copies of %d, got %d", copies, i, c)
+		}
+		all := tree.GetAll(seqItem{key: i})
+		for c, item := range all {
+			if item != (seqItem{i, c}) {
+				t.Fatalf("expecting %v in insertion order, got %v", seqItem{i, c}, all)
+			}
+		}
+	}
+	if tree.Count(seqItem{key: n}) != 0 || tree.GetAll(seqItem{key: -1}) != nil {
+		t.Errorf("found non-existent key")
+	}
+	// Deleting unrelated items rotates the tree without reordering equal items
+	for i := 0; i < n; i += 2 {
+		tree.DeleteAll(seqItem{key: i})
+	}
+	checkLLRB(t, tree)
+	var got []Item
+	tree.AscendEqual(seqItem{key: 101}, func(i Item) bool {
+		got = append(got, i)
+		return len(got) < 3
+	})
+	expected := []Item{seqItem{101, 0}, seqItem{101, 1}, seqItem{101, 2}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestMultisetDelete(t *testing.T) {
+	n, copies := 100, 4
+	tree := newMultiset(n, copies)
+	if d := tree.DeleteAll(seqItem{key: 7}); d != copies {
+		t.Errorf("expecting %d deleted, got %d", copies, d)
+	}
+	if d := tree.DeleteAll(seqItem{key: 7}); d != 0 {
+		t.Errorf("deleted non-existent items")
+	}
+	if tree.Len() != (n-1)*copies {
+		t.Errorf("expecting len %d, got %d", (n-1)*copies, tree.Len())
+	}
+	if d := tree.DeleteExact(seqItem{8, 2}, sameSeq); d != (seqItem{8, 2}) {
+		t.Errorf("expecting to delete %v, got %v", seqItem{8, 2}, d)
+	}
+	if d := tree.DeleteExact(seqItem{8, 2}, sameSeq); d != nil {
+		t.Errorf("deleted %v twice", d)
+	}
+	expected := []Item{seqItem{8, 0}, seqItem{8, 1}, seqItem{8, 3}}
+	if got := tree.GetAll(seqItem{key: 8}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+	if tree.Len() != (n-1)*copies-1 {
+		t.Errorf("expecting len %d, got %d", (n-1)*copies-1, tree.Len())
+	}
+	checkLLRB(t, tree)
+}
+
+func TestMultisetDeleteBalance(t *testing.T) {
+	for trial := 0; trial < 200; trial++ {
+		n := 4 + rand.Intn(30)
+		tree := newMultiset(n, 3)
+		for i := 0; i < 2*n; i++ {
+			tree.Delete(seqItem{key: rand.Intn(n)})
+			checkLLRB(t, tree)
+		}
+	}
+}
+
+func TestMultisetDeleteExactRandom(t *testing.T) {
+	for _, ranked := range []bool{false, true} {
+		tree, seq := New(), 0
+		if ranked {
+			tree.EnableRank()
+		}
+		var expected []Item // sorted by key, then by seq
+		for i := 0; i < 2000; i++ {
+			key := rand.Intn(20)
+			at := 0
+			for at < len(expected) && expected[at].(seqItem).key <= key {
+				at++
+			}
+			switch op := rand.Intn(10); {
+			case op < 6:
+				seq++
+				tree.InsertNoReplace(seqItem{key, seq})
+				expected = append(expected[:at], append([]Item{seqItem{key, seq}}, expected[at:]...)...)
+			case op < 9:
+				var victim Item
+				for _, item := range expected {
+					if item.(seqItem).key == key && rand.Intn(2) == 0 {
+						victim = item
+						break
+					}
+				}
+				if victim == nil {
+					victim = seqItem{key, -1}
+				}
+				snapshot := tree.Snapshot()
+				before := collect(snapshot.Ascend)
+				d := tree.DeleteExact(victim, sameSeq)
+				if victim.(seqItem).seq < 0 {
+					if d != nil {
+						t.Fatalf("deleted %v instead of nothing", d)
+					}
+					break
+				}
+				if d != victim {
+					t.Fatalf("deleted %v instead of %v", d, victim)
+				}
+				for j, item := range expected {
+					if item == victim {
+						expected = append(expected[:j], expected[j+1:]...)
+						break
+					}
+				}
+				if got := collect(snapshot.Ascend); !reflect.DeepEqual(got, before) {
+					t.Fatalf("DeleteExact changed a snapshot")
+				}
+			default:
+				n := 0
+				for j := 0; j < len(expected); {
+					if expected[j].(seqItem).key == key {
+						expected = append(expected[:j], expected[j+1:]...)
+						n++
+					} else {
+						j++
+					}
+				}
+				if d := tree.DeleteAll(seqItem{key: key}); d != n {
+					t.Fatalf("expecting %d deleted, got %d", n, d)
+				}
+			}
+			if tree.Len() != len(expected) {
+				t.Fatalf("expecting len %d, got %d", len(expected), tree.Len())
+			}
+			checkLLRB(t, tree)
+		}
+		if got := collect(tree.Ascend); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v but got %v", expected, got)
+		}
+	}
+}
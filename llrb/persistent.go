@@ -0,0 +1,167 @@
+package llrb
+
+// Persistent is an immutable LLRB. Its mutators leave the receiver unchanged and
+// return a new version of the tree instead, built by copying only the nodes on the
+// paths that the mutation modifies. All versions share their remaining nodes and
+// stay valid indefinitely, so keeping an old version is an O(1) snapshot.
+//
+// A Persistent may be read from any number of goroutines at once.
+type Persistent struct {
+	tree LLRB // Never modified after the version is created
+}
+
+// NewPersistent allocates a new empty persistent tree whose items implement Lesser.
+func NewPersistent() *Persistent {
+	return &Persistent{tree: *New()}
+}
+
+// NewPersistentWith allocates a new empty persistent tree that orders its items with less.
+func NewPersistentWith(less LessFunc) *Persistent {
+	return &Persistent{tree: *NewWith(less)}
+}
+
+// Snapshot returns the current contents of t as a persistent tree in O(1) time.
+// The snapshot shares its nodes with t, which copies them before any later
+// modification, so subsequent changes to t do not affect the snapshot.
+//
+// Snapshot only reads t, so several goroutines holding a read lock on t may take
+// snapshots at once.
+func (t *LLRB) Snapshot() *Persistent {
+	t.share()
+	return &Persistent{tree: *t}
+}
+
+// next returns a copy of p that owns none of the nodes it shares with p,
+// so that modifying it leaves p intact.
+func (p *Persistent) next() *Persistent {
+	q := &Persistent{tree: p.tree}
+	q.tree.cow = new(cowToken)
+	return q
+}
+
+// Len returns the number of nodes in the tree.
+func (p *Persistent) Len() int { return p.tree.Len() }
+
+// Has returns true if the tree contains an element whose order is the same as that of key.
+func (p *Persistent) Has(key Item) bool { return p.tree.Has(key) }
+
+// Get retrieves an element from the tree whose order is the same as that of key.
+func (p *Persistent) Get(key Item) Item { return p.tree.Get(key) }
+
+// Min returns the minimum element in the tree.
+func (p *Persistent) Min() Item { return p.tree.Min() }
+
+// Max returns the maximum element in the tree.
+func (p *Persistent) Max() Item { return p.tree.Max() }
+
+// Floor returns the greatest element in the tree that is less than or equal to key.
+func (p *Persistent) Floor(key Item) Item { return p.tree.Floor(key) }
+
+// Ceiling returns the least element in the tree that is greater than or equal to key.
+func (p *Persistent) Ceiling(key Item) Item { return p.tree.Ceiling(key) }
+
+// Lower returns the greatest element in the tree that is strictly less than key.
+func (p *Persistent) Lower(key Item) Item { return p.tree.Lower(key) }
+
+// Higher returns the least element in the tree that is strictly greater than key.
+func (p *Persistent) Higher(key Item) Item { return p.tree.Higher(key) }
+
+// ReplaceOrInsert returns a version of the tree with item inserted. If an existing
+// element has the same order, it is replaced in the new version and returned.
+func (p *Persistent) ReplaceOrInsert(item Item) (*Persistent, Item) {
+	q := p.next()
+	replaced := q.tree.ReplaceOrInsert(item)
+	return q, replaced
+}
+
+// InsertNoReplace returns a version of the tree with item inserted. If an existing
+// element has the same order, both elements remain in the new version.
+func (p *Persistent) InsertNoReplace(item Item) *Persistent {
+	q := p.next()
+	q.tree.InsertNoReplace(item)
+	return q
+}
+
+// Delete returns a version of the tree without the element whose order is the same
+// as that of key, and the deleted element. If there is no such element, Delete
+// returns p itself and nil.
+func (p *Persistent) Delete(key Item) (*Persistent, Item) {
+	if !p.Has(key) {
+		return p, nil
+	}
+	q := p.next()
+	deleted := q.tree.Delete(key)
+	return q, deleted
+}
+
+// DeleteMin returns a version of the tree without its minimum element, and the
+// deleted element. If the tree is empty, DeleteMin returns p itself and nil.
+func (p *Persistent) DeleteMin() (*Persistent, Item) {
+	if p.Len() == 0 {
+		return p, nil
+	}
+	q := p.next()
+	deleted := q.tree.DeleteMin()
+	return q, deleted
+}
+
+// DeleteMax returns a version of the tree without its maximum element, and the
+// deleted element. If the tree is empty, DeleteMax returns p itself and nil.
+func (p *Persistent) DeleteMax() (*Persistent, Item) {
+	if p.Len() == 0 {
+		return p, nil
+	}
+	q := p.next()
+	deleted := q.tree.DeleteMax()
+	return q, deleted
+}
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (p *Persistent) Ascend(iterator ItemIterator) {
+	p.tree.Ascend(iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (p *Persistent) Descend(iterator ItemIterator) {
+	p.tree.Descend(iterator)
+}
+
+// AscendRange will call iterator once for each element greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (p *Persistent) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	p.tree.AscendRange(greaterOrEqual, lessThan, iterator)
+}
+
+// AscendGreaterOrEqual will call iterator once for each element greater or equal to
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (p *Persistent) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
+	p.tree.AscendGreaterOrEqual(pivot, iterator)
+}
+
+// AscendLessThan will call iterator once for each element lower than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (p *Persistent) AscendLessThan(pivot Item, iterator ItemIterator) {
+	p.tree.AscendLessThan(pivot, iterator)
+}
+
+// DescendLessOrEqual will call iterator once for each element less than or equal to
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (p *Persistent) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
+	p.tree.DescendLessOrEqual(pivot, iterator)
+}
+
+// DescendRange will call iterator once for each element less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (p *Persistent) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	p.tree.DescendRange(lessOrEqual, greaterThan, iterator)
+}
+
+// Range will call iterator once for each element between the bounds lo and hi,
+// in the order given by dir. It will stop whenever the iterator returns false.
+func (p *Persistent) Range(lo, hi Bound, dir Direction, iterator ItemIterator) {
+	p.tree.Range(lo, hi, dir, iterator)
+}
@@ -0,0 +1,209 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testTree is the part of the LLRB API that the randomized tests in llrb_test.go
+// exercise, so that forEachTree can run them over snapshots and persistent
+// versions as well as over a plain LLRB.
+type testTree interface {
+	ReplaceOrInsert(item Item) Item
+	Delete(key Item) Item
+	AscendGreaterOrEqual(pivot Item, iterator ItemIterator)
+	HeightStats() (avg, stddev float64)
+}
+
+// forEachTree runs test over a plain LLRB, over an LLRB that is snapshotted as it
+// is modified, and over a Persistent, and then checks that the snapshots and the
+// old persistent versions still hold what they held when they were taken.
+func forEachTree(t *testing.T, test func(t *testing.T, tree testTree)) {
+	t.Run("LLRB", func(t *testing.T) { test(t, New()) })
+	t.Run("Snapshot", func(t *testing.T) {
+		s := &snapshotTree{LLRB: New()}
+		test(t, s)
+		s.check(t)
+	})
+	t.Run("Persistent", func(t *testing.T) {
+		p := &persistentTree{Persistent: NewPersistent()}
+		test(t, p)
+		p.check(t)
+	})
+}
+
+// versions keeps the versions of a tree before its 1st, 2nd, 4th, 8th... modification,
+// which takes linear time overall, along with their contents.
+type versions struct {
+	n        int
+	kept     []*Persistent
+	contents [][]Item
+}
+
+// due counts a modification, and returns true if the version before it should be kept.
+func (v *versions) due() bool {
+	v.n++
+	return v.n&(v.n-1) == 0
+}
+
+func (v *versions) keep(p *Persistent) {
+	v.kept = append(v.kept, p)
+	v.contents = append(v.contents, collect(p.Ascend))
+}
+
+func (v *versions) check(t *testing.T) {
+	for i, p := range v.kept {
+		checkLLRB(t, &p.tree)
+		if got := collect(p.Ascend); !reflect.DeepEqual(got, v.contents[i]) {
+			t.Errorf("version %d changed", i)
+		}
+	}
+}
+
+type snapshotTree struct {
+	*LLRB
+	versions
+}
+
+func (s *snapshotTree) ReplaceOrInsert(item Item) Item {
+	if s.due() {
+		s.keep(s.Snapshot())
+	}
+	return s.LLRB.ReplaceOrInsert(item)
+}
+
+func (s *snapshotTree) Delete(key Item) Item {
+	if s.due() {
+		s.keep(s.Snapshot())
+	}
+	return s.LLRB.Delete(key)
+}
+
+type persistentTree struct {
+	*Persistent
+	versions
+}
+
+func (p *persistentTree) ReplaceOrInsert(item Item) (replaced Item) {
+	if p.due() {
+		p.keep(p.Persistent)
+	}
+	p.Persistent, replaced = p.Persistent.ReplaceOrInsert(item)
+	return replaced
+}
+
+func (p *persistentTree) Delete(key Item) (deleted Item) {
+	if p.due() {
+		p.keep(p.Persistent)
+	}
+	p.Persistent, deleted = p.Persistent.Delete(key)
+	return deleted
+}
+
+func (p *persistentTree) HeightStats() (avg, stddev float64) { return p.tree.HeightStats() }
+
+func TestPersistentDeleteMinMax(t *testing.T) {
+	p := NewPersistent()
+	for _, i := range rand.Perm(100) {
+		p = p.InsertNoReplace(Int(i))
+	}
+	q, min := p.DeleteMin()
+	r, max := q.DeleteMax()
+	if min != Int(0) || max != Int(99) || p.Len() != 100 || q.Len() != 99 || r.Len() != 98 {
+		t.Errorf("bad versions after deleting the minimum and maximum")
+	}
+	checkLLRB(t, &r.tree)
+	if s, d := r.Delete(Int(200)); s != r || d != nil {
+		t.Errorf("deleting a missing item made a new version")
+	}
+	empty := NewPersistent()
+	if s, d := empty.DeleteMin(); s != empty || d != nil {
+		t.Errorf("deleting from an empty version made a new version")
+	}
+	if s, d := empty.DeleteMax(); s != empty || d != nil {
+		t.Errorf("deleting from an empty version made a new version")
+	}
+}
+
+func TestPersistentReplace(t *testing.T) {
+	p := NewPersistentWith(lessInt)
+	for i := 0; i < 100; i++ {
+		p, _ = p.ReplaceOrInsert(i)
+	}
+	q, replaced := p.ReplaceOrInsert(50)
+	if replaced != 50 || q.Len() != 100 || p.Len() != 100 {
+		t.Errorf("bad replacement")
+	}
+	var ary []Item
+	q.AscendRange(10, 13, func(i Item) bool {
+		ary = append(ary, i)
+		return true
+	})
+	if !reflect.DeepEqual(ary, []Item{10, 11, 12}) {
+		t.Errorf("expected [10 11 12] but got %v", ary)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tree := New()
+	tree.EnableRank()
+	tree.SetAugment(sumAugment)
+	n := 500
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	var snapshots []*Persistent
+	var contents [][]Item
+	for round := 0; round < 5; round++ {
+		snapshots = append(snapshots, tree.Snapshot())
+		contents = append(contents, collect(snapshots[round].Ascend))
+		for i := 0; i < n/5; i++ {
+			if rand.Intn(2) == 0 {
+				tree.Delete(Int(rand.Intn(n)))
+			} else {
+				tree.InsertNoReplace(Int(rand.Intn(n)))
+			}
+		}
+		checkLLRB(t, tree)
+		checkSummaries(t, tree, tree.root)
+	}
+	for i, s := range snapshots {
+		checkLLRB(t, &s.tree)
+		checkSummaries(t, &s.tree, s.tree.root)
+		if got := collect(s.Ascend); !reflect.DeepEqual(got, contents[i]) {
+			t.Errorf("snapshot %d changed", i)
+		}
+	}
+}
+
+func TestSnapshotUnderReadLock(t *testing.T) {
+	tree := intTree(true, rand.Perm(1000)...)
+	items := collect(tree.Ascend)
+	var mu sync.RWMutex
+	snapshots := make([]*Persistent, 4)
+	var wg sync.WaitGroup
+	for i := range snapshots {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mu.RLock()
+			defer mu.RUnlock()
+			snapshots[i] = tree.Snapshot()
+		}(i)
+	}
+	wg.Wait()
+	mu.Lock()
+	for i := 0; i < 1000; i += 2 {
+		tree.Delete(Int(i))
+	}
+	mu.Unlock()
+	checkLLRB(t, tree)
+	for _, p := range snapshots {
+		checkLLRB(t, &p.tree)
+		if got := collect(p.Ascend); !reflect.DeepEqual(got, items) {
+			t.Fatalf("modifying the tree changed a snapshot")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package llrb
+
+// Bound is one end of the range of elements visited by Range. The zero Bound is unbounded.
+type Bound struct {
+	item Item
+	kind boundKind
+}
+
+type boundKind int8
+
+const (
+	unbounded boundKind = iota
+	included
+	excluded
+)
+
+// Included returns a bound that admits item itself and everything beyond it.
+func Included(item Item) Bound { return Bound{item, included} }
+
+// Excluded returns a bound that admits everything strictly beyond item.
+func Excluded(item Item) Bound { return Bound{item, excluded} }
+
+// Unbounded returns a bound that admits every element on its side of the range.
+func Unbounded() Bound { return Bound{} }
+
+// Direction is the order in which Range visits elements.
+type Direction int
+
+const (
+	Ascending Direction = iota
+	Descending
+)
+
+// Range will call iterator once for each element between the bounds lo and hi,
+// in the order given by dir. It will stop whenever the iterator returns false.
+func (t *LLRB) Range(lo, hi Bound, dir Direction, iterator ItemIterator) {
+	switch dir {
+	case Ascending:
+		t.ascendBounds(t.root, lo, hi, t.guard(iterator))
+	case Descending:
+		t.descendBounds(t.root, lo, hi, t.guard(iterator))
+	default:
+		panic("direction")
+	}
+}
+
+// aboveLo returns true if item is on the admitted side of the lower bound lo.
+func (t *LLRB) aboveLo(lo Bound, item Item) bool {
+	switch lo.kind {
+	case included:
+		return !t.less(item, lo.item)
+	case excluded:
+		return t.less(lo.item, item)
+	}
+	return true
+}
+
+// belowHi returns true if item is on the admitted side of the upper bound hi.
+func (t *LLRB) belowHi(hi Bound, item Item) bool {
+	switch hi.kind {
+	case included:
+		return !t.less(hi.item, item)
+	case excluded:
+		return t.less(item, hi.item)
+	}
+	return true
+}
+
+func (t *LLRB) ascendBounds(h *Node, lo, hi Bound, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if !t.belowHi(hi, h.Item) {
+		return t.ascendBounds(h.Left, lo, hi, iterator)
+	}
+	if !t.aboveLo(lo, h.Item) {
+		return t.ascendBounds(h.Right, lo, hi, iterator)
+	}
+
+	if !t.ascendBounds(h.Left, lo, hi, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.ascendBounds(h.Right, lo, hi, iterator)
+}
+
+func (t *LLRB) descendBounds(h *Node, lo, hi Bound, iterator ItemIterator) bool {
+	if h == nil {
+		return true
+	}
+	if !t.belowHi(hi, h.Item) {
+		return t.descendBounds(h.Left, lo, hi, iterator)
+	}
+	if !t.aboveLo(lo, h.Item) {
+		return t.descendBounds(h.Right, lo, hi, iterator)
+	}
+
+	if !t.descendBounds(h.Right, lo, hi, iterator) {
+		return false
+	}
+	if !iterator(h.Item) {
+		return false
+	}
+	return t.descendBounds(h.Left, lo, hi, iterator)
+}
@@ -0,0 +1,75 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRangeAllBounds(t *testing.T) {
+	tree := New()
+	for _, i := range rand.Perm(10) {
+		tree.InsertNoReplace(Int(i))
+	}
+	tree.InsertNoReplace(Int(5)) // a duplicate at a bound
+	var bounds []Bound
+	bounds = append(bounds, Unbounded())
+	for k := -1; k <= 10; k++ {
+		bounds = append(bounds, Included(Int(k)), Excluded(Int(k)))
+	}
+	for _, lo := range bounds {
+		for _, hi := range bounds {
+			var expected []Item
+			tree.Ascend(func(i Item) bool {
+				if tree.aboveLo(lo, i) && tree.belowHi(hi, i) {
+					expected = append(expected, i)
+				}
+				return true
+			})
+			ary := collect(func(i ItemIterator) { tree.Range(lo, hi, Ascending, i) })
+			if !reflect.DeepEqual(ary, expected) {
+				t.Errorf("ascending %v..%v: expected %v but got %v", lo, hi, expected, ary)
+			}
+			for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+				expected[i], expected[j] = expected[j], expected[i]
+			}
+			ary = collect(func(i ItemIterator) { tree.Range(lo, hi, Descending, i) })
+			if !reflect.DeepEqual(ary, expected) {
+				t.Errorf("descending %v..%v: expected %v but got %v", lo, hi, expected, ary)
+			}
+		}
+	}
+}
+
+func TestRangeBounds(t *testing.T) {
+	tree := New()
+	tree.ReplaceOrInsertBulk(Int(1), Int(2), Int(3), Int(4))
+	cases := []struct {
+		lo, hi   Bound
+		dir      Direction
+		expected []Item
+	}{
+		{Included(Int(2)), Included(Int(3)), Ascending, []Item{Int(2), Int(3)}},
+		{Included(Int(2)), Excluded(Int(3)), Ascending, []Item{Int(2)}},
+		{Excluded(Int(2)), Included(Int(3)), Descending, []Item{Int(3)}},
+		{Excluded(Int(2)), Unbounded(), Descending, []Item{Int(4), Int(3)}},
+		{Unbounded(), Excluded(Int(3)), Ascending, []Item{Int(1), Int(2)}},
+		{Unbounded(), Unbounded(), Descending, []Item{Int(4), Int(3), Int(2), Int(1)}},
+		{Excluded(Int(2)), Excluded(Int(3)), Ascending, nil},
+		{Included(Int(3)), Included(Int(2)), Ascending, nil},
+	}
+	for _, c := range cases {
+		ary := collect(func(i ItemIterator) { tree.Range(c.lo, c.hi, c.dir, i) })
+		if !reflect.DeepEqual(ary, c.expected) {
+			t.Errorf("%v..%v: expected %v but got %v", c.lo, c.hi, c.expected, ary)
+		}
+	}
+	var ary []Item
+	tree.Range(Unbounded(), Unbounded(), Ascending, func(i Item) bool {
+		ary = append(ary, i)
+		return len(ary) < 3
+	})
+	if expected := []Item{Int(1), Int(2), Int(3)}; !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
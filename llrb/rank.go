@@ -0,0 +1,78 @@
+package llrb
+
+// Order statistics are available in trees that have called EnableRank. Such trees
+// keep the size of every subtree in its root node, which lets Rank, Select and
+// CountRange run in O(log n) time.
+
+// EnableRank makes t maintain subtree sizes from now on, computing them for the
+// nodes already in the tree. Maintaining sizes adds a small constant cost to every
+// rotation and every insertion or deletion.
+func (t *LLRB) EnableRank() {
+	if t.ranked {
+		return
+	}
+	t.ranked = true
+	t.root = t.updateAll(t.root)
+	t.mods++
+}
+
+// Ranked returns true if t maintains subtree sizes.
+func (t *LLRB) Ranked() bool { return t.ranked }
+
+func size(h *Node) int {
+	if h == nil || h.aug == nil {
+		return 0
+	}
+	return h.aug.size
+}
+
+func (t *LLRB) mustBeRanked() {
+	if !t.ranked {
+		panic("rank not enabled")
+	}
+}
+
+// Rank returns the number of elements in the tree that are less than key,
+// which is the position key has or would have in ascending order.
+func (t *LLRB) Rank(key Item) int {
+	t.mustBeRanked()
+	r := 0
+	h := t.root
+	for h != nil {
+		if t.less(h.Item, key) {
+			r += size(h.Left) + 1
+			h = h.Right
+		} else {
+			h = h.Left
+		}
+	}
+	return r
+}
+
+// Select returns the element at position i in ascending order, counting from zero,
+// or nil if i is out of range.
+func (t *LLRB) Select(i int) Item {
+	t.mustBeRanked()
+	h := t.root
+	for h != nil {
+		switch l := size(h.Left); {
+		case i < l:
+			h = h.Left
+		case i > l:
+			i -= l + 1
+			h = h.Right
+		default:
+			return h.Item
+		}
+	}
+	return nil
+}
+
+// CountRange returns the number of elements greater or equal to greaterOrEqual
+// and less than lessThan.
+func (t *LLRB) CountRange(greaterOrEqual, lessThan Item) int {
+	if n := t.Rank(lessThan) - t.Rank(greaterOrEqual); n > 0 {
+		return n
+	}
+	return 0
+}
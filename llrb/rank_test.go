@@ -0,0 +1,114 @@
+package llrb
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRankSelect(t *testing.T) {
+	tree := New()
+	tree.EnableRank()
+	n := 1000
+	for _, i := range rand.Perm(n) {
+		tree.ReplaceOrInsert(Int(2 * i))
+	}
+	checkLLRB(t, tree)
+	for i := 0; i < n; i++ {
+		if r := tree.Rank(Int(2 * i)); r != i {
+			t.Fatalf("expecting rank %d for %d, got %d", i, 2*i, r)
+		}
+		if r := tree.Rank(Int(2*i + 1)); r != i+1 {
+			t.Fatalf("expecting rank %d for %d, got %d", i+1, 2*i+1, r)
+		}
+		if s := tree.Select(i); s != Int(2*i) {
+			t.Fatalf("expecting %d at %d, got %v", 2*i, i, s)
+		}
+	}
+	if tree.Select(-1) != nil || tree.Select(n) != nil {
+		t.Errorf("selected out of range")
+	}
+	if tree.Rank(Inf(-1)) != 0 || tree.Rank(Inf(1)) != n {
+		t.Errorf("bad rank of infinities")
+	}
+	if c := tree.CountRange(Int(10), Int(20)); c != 5 {
+		t.Errorf("expecting 5 in range, got %d", c)
+	}
+	if c := tree.CountRange(Int(20), Int(10)); c != 0 {
+		t.Errorf("expecting empty range, got %d", c)
+	}
+	if c := tree.CountRange(Inf(-1), Inf(1)); c != n {
+		t.Errorf("expecting %d in range, got %d", n, c)
+	}
+}
+
+func TestRankMaintenance(t *testing.T) {
+	tree := New()
+	n := 500
+	for _, i := range rand.Perm(n) {
+		tree.InsertNoReplace(Int(i))
+	}
+	tree.EnableRank()
+	checkLLRB(t, tree)
+	for _, i := range rand.Perm(n) {
+		tree.InsertNoReplace(Int(i))
+	}
+	checkLLRB(t, tree)
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0:
+			tree.DeleteMin()
+		case 1:
+			tree.DeleteMax()
+		default:
+			tree.Delete(Int(rand.Intn(n)))
+		}
+		checkLLRB(t, tree)
+	}
+	prev := -1
+	for i := 0; i < tree.Len(); i++ {
+		v := int(tree.Select(i).(Int))
+		if v < prev {
+			t.Fatalf("select out of order")
+		}
+		if r := tree.Rank(Int(v)); r > i || tree.Select(r) != Int(v) {
+			t.Fatalf("rank and select disagree at %d", i)
+		}
+		prev = v
+	}
+}
+
+func TestRankNotEnabled(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic")
+		}
+	}()
+	New().Rank(Int(1))
+}
+
+func TestRankNodeFields(t *testing.T) {
+	tree := intTree(false, rand.Perm(100)...)
+	for h := tree.Root(); h != nil; h = h.Left {
+		if h.aug != nil {
+			t.Fatalf("expected the nodes of a plain tree to hold no sizes or summaries")
+		}
+	}
+	tree.EnableRank()
+	tree.SetAugment(sumAugment)
+	snapshot := tree.Snapshot()
+	for i := 0; i < 100; i += 2 {
+		tree.Delete(Int(i))
+	}
+	checkLLRB(t, tree)
+	checkSummaries(t, tree, tree.root)
+	// The snapshot keeps the sizes and summaries of its own nodes.
+	checkLLRB(t, &snapshot.tree)
+	checkSummaries(t, &snapshot.tree, snapshot.tree.root)
+	if snapshot.tree.Select(99) != Int(99) || tree.Select(49) != Int(99) {
+		t.Errorf("bad sizes after modifying a snapshotted tree")
+	}
+	tree.SetAugment(nil)
+	if tree.Root().aug == nil || summary(tree.Root()) != nil {
+		t.Errorf("expected a ranked tree to keep its sizes only")
+	}
+}
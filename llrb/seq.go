@@ -0,0 +1,82 @@
+//go:build go1.23
+
+package llrb
+
+import "iter"
+
+// The methods in this file return the traversals of iterator.go and range.go
+// as iter.Seq values, for use with range-over-func loops:
+//
+//	for item := range tree.All() {
+//		...
+//	}
+//
+// Breaking out of the loop stops the underlying traversal.
+
+func seq(walk func(ItemIterator)) iter.Seq[Item] {
+	return func(yield func(Item) bool) { walk(yield) }
+}
+
+// All returns a sequence of the elements in ascending order.
+func (t *LLRB) All() iter.Seq[Item] {
+	return seq(t.Ascend)
+}
+
+// Backward returns a sequence of the elements in descending order.
+func (t *LLRB) Backward() iter.Seq[Item] {
+	return seq(t.Descend)
+}
+
+// AscendRangeSeq returns a sequence of the elements greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+func (t *LLRB) AscendRangeSeq(greaterOrEqual, lessThan Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.AscendRange(greaterOrEqual, lessThan, i) })
+}
+
+// AscendGreaterOrEqualSeq returns a sequence of the elements greater or equal to
+// pivot, in ascending order.
+func (t *LLRB) AscendGreaterOrEqualSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.AscendGreaterOrEqual(pivot, i) })
+}
+
+// AscendGreaterThanSeq returns a sequence of the elements greater than pivot,
+// in ascending order.
+func (t *LLRB) AscendGreaterThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.AscendGreaterThan(pivot, i) })
+}
+
+// AscendLessThanSeq returns a sequence of the elements less than pivot,
+// in ascending order.
+func (t *LLRB) AscendLessThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.AscendLessThan(pivot, i) })
+}
+
+// DescendRangeSeq returns a sequence of the elements less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+func (t *LLRB) DescendRangeSeq(lessOrEqual, greaterThan Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.DescendRange(lessOrEqual, greaterThan, i) })
+}
+
+// DescendLessOrEqualSeq returns a sequence of the elements less than or equal to
+// pivot, in descending order.
+func (t *LLRB) DescendLessOrEqualSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.DescendLessOrEqual(pivot, i) })
+}
+
+// DescendGreaterThanSeq returns a sequence of the elements greater than pivot,
+// in descending order.
+func (t *LLRB) DescendGreaterThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.DescendGreaterThan(pivot, i) })
+}
+
+// RangeSeq returns a sequence of the elements between the bounds lo and hi,
+// in the order given by dir.
+func (t *LLRB) RangeSeq(lo, hi Bound, dir Direction) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.Range(lo, hi, dir, i) })
+}
+
+// AscendEqualSeq returns a sequence of the elements whose order is the same as
+// that of key, in insertion order.
+func (t *LLRB) AscendEqualSeq(key Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { t.AscendEqual(key, i) })
+}
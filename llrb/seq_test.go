@@ -0,0 +1,129 @@
+//go:build go1.23
+
+package llrb
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	tree := New()
+	tree.ReplaceOrInsertBulk(Int(4), Int(6), Int(1), Int(3))
+	cases := []struct {
+		name string
+		seq  iter.Seq[Item]
+		walk func(ItemIterator)
+	}{
+		{"All", tree.All(), tree.Ascend},
+		{"Backward", tree.Backward(), tree.Descend},
+		{"AscendRangeSeq", tree.AscendRangeSeq(Int(3), Int(6)),
+			func(i ItemIterator) { tree.AscendRange(Int(3), Int(6), i) }},
+		{"AscendGreaterOrEqualSeq", tree.AscendGreaterOrEqualSeq(Int(3)),
+			func(i ItemIterator) { tree.AscendGreaterOrEqual(Int(3), i) }},
+		{"AscendGreaterThanSeq", tree.AscendGreaterThanSeq(Int(3)),
+			func(i ItemIterator) { tree.AscendGreaterThan(Int(3), i) }},
+		{"AscendLessThanSeq", tree.AscendLessThanSeq(Int(4)),
+			func(i ItemIterator) { tree.AscendLessThan(Int(4), i) }},
+		{"DescendRangeSeq", tree.DescendRangeSeq(Int(6), Int(1)),
+			func(i ItemIterator) { tree.DescendRange(Int(6), Int(1), i) }},
+		{"DescendLessOrEqualSeq", tree.DescendLessOrEqualSeq(Int(4)),
+			func(i ItemIterator) { tree.DescendLessOrEqual(Int(4), i) }},
+		{"DescendGreaterThanSeq", tree.DescendGreaterThanSeq(Int(1)),
+			func(i ItemIterator) { tree.DescendGreaterThan(Int(1), i) }},
+		{"RangeSeq", tree.RangeSeq(Excluded(Int(1)), Included(Int(4)), Descending),
+			func(i ItemIterator) { tree.Range(Excluded(Int(1)), Included(Int(4)), Descending, i) }},
+	}
+	for _, c := range cases {
+		var ary []Item
+		for item := range c.seq {
+			ary = append(ary, item)
+		}
+		if expected := collect(c.walk); !reflect.DeepEqual(ary, expected) {
+			t.Errorf("%s: expected %v but got %v", c.name, expected, ary)
+		}
+	}
+}
+
+func TestSeqBreak(t *testing.T) {
+	tree := New()
+	for i := 0; i < 100; i++ {
+		tree.ReplaceOrInsert(Int(i))
+	}
+	var ary []Item
+	for item := range tree.All() {
+		if item == Int(3) {
+			break
+		}
+		ary = append(ary, item)
+	}
+	if expected := []Item{Int(0), Int(1), Int(2)}; !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	// Breaking out of a nested loop must stop both traversals cleanly.
+	n := 0
+outer:
+	for a := range tree.Backward() {
+		for b := range tree.All() {
+			if b == a {
+				continue outer
+			}
+			if a == Int(90) {
+				break outer
+			}
+			n++
+		}
+	}
+	if expected := 99 + 98 + 97 + 96 + 95 + 94 + 93 + 92 + 91; n != expected {
+		t.Errorf("expected %d inner iterations but got %d", expected, n)
+	}
+}
+
+func TestAscendEqualSeq(t *testing.T) {
+	tree := newMultiset(50, 4)
+	var ary []Item
+	for item := range tree.AscendEqualSeq(seqItem{key: 17}) {
+		ary = append(ary, item)
+	}
+	expected := []Item{seqItem{17, 0}, seqItem{17, 1}, seqItem{17, 2}, seqItem{17, 3}}
+	if !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = nil
+	for item := range tree.AscendEqualSeq(seqItem{key: 17}) {
+		if item == (seqItem{17, 2}) {
+			break
+		}
+		ary = append(ary, item)
+	}
+	if !reflect.DeepEqual(ary, expected[:2]) {
+		t.Errorf("expected %v but got %v", expected[:2], ary)
+	}
+	for item := range tree.AscendEqualSeq(seqItem{key: 50}) {
+		t.Errorf("found non-existent %v", item)
+	}
+}
+
+func TestSyncSeq(t *testing.T) {
+	s := NewSync()
+	for i := 0; i < 10; i++ {
+		s.ReplaceOrInsert(Int(i))
+	}
+	// The body of the loop may modify the tree.
+	var ary []Item
+	for item := range s.AscendRangeSeq(Int(2), Int(5)) {
+		s.Delete(item)
+		ary = append(ary, item)
+	}
+	if expected := []Item{Int(2), Int(3), Int(4)}; !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+	ary = nil
+	for item := range s.Backward() {
+		ary = append(ary, item)
+	}
+	if expected := collect(s.Descend); !reflect.DeepEqual(ary, expected) {
+		t.Errorf("expected %v but got %v", expected, ary)
+	}
+}
@@ -0,0 +1,138 @@
+package llrb
+
+// Set is an ordered set of distinct items.
+//
+// The binary operations (Union, Intersect, Difference, SymmetricDifference, IsSubset
+// and Equal) merge the two sets in a single linear pass and build their results in
+// linear time, so they cost O(n+m) rather than the O(n log m) of probing one set
+// with the items of the other. Both operands must be ordered the same way; the
+// result is ordered like the receiver.
+type Set struct {
+	tree *LLRB
+}
+
+// NewSet allocates a new set whose items implement Lesser.
+func NewSet() *Set {
+	return &Set{tree: New()}
+}
+
+// NewSetWith allocates a new set that orders its items with less.
+func NewSetWith(less LessFunc) *Set {
+	return &Set{tree: NewWith(less)}
+}
+
+// Len returns the number of items in the set.
+func (s *Set) Len() int { return s.tree.Len() }
+
+// Has returns true if the set contains an item whose order is the same as that of key.
+func (s *Set) Has(key Item) bool { return s.tree.Has(key) }
+
+// Get retrieves the item in the set whose order is the same as that of key.
+func (s *Set) Get(key Item) Item { return s.tree.Get(key) }
+
+// Min returns the minimum item in the set.
+func (s *Set) Min() Item { return s.tree.Min() }
+
+// Max returns the maximum item in the set.
+func (s *Set) Max() Item { return s.tree.Max() }
+
+// Insert adds item to the set. If an existing item has the same order,
+// it is replaced and returned.
+func (s *Set) Insert(item Item) Item { return s.tree.ReplaceOrInsert(item) }
+
+// Delete removes the item whose order is the same as that of key and returns it.
+func (s *Set) Delete(key Item) Item { return s.tree.Delete(key) }
+
+// Ascend will call iterator once for each item in ascending order.
+// It will stop whenever the iterator returns false.
+func (s *Set) Ascend(iterator ItemIterator) {
+	s.tree.Ascend(iterator)
+}
+
+// AscendRange will call iterator once for each item greater or equal to greaterOrEqual
+// and less than lessThan, in ascending order. It will stop whenever the iterator returns false.
+func (s *Set) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	s.tree.AscendRange(greaterOrEqual, lessThan, iterator)
+}
+
+// Union returns a new set holding the items of either set.
+// Items present in both are taken from s.
+func (s *Set) Union(other *Set) *Set {
+	return s.merge(other, true, true, true)
+}
+
+// Intersect returns a new set holding the items of s that are also in other.
+func (s *Set) Intersect(other *Set) *Set {
+	return s.merge(other, false, true, false)
+}
+
+// Difference returns a new set holding the items of s that are not in other.
+func (s *Set) Difference(other *Set) *Set {
+	return s.merge(other, true, false, false)
+}
+
+// SymmetricDifference returns a new set holding the items that are in exactly one of the sets.
+func (s *Set) SymmetricDifference(other *Set) *Set {
+	return s.merge(other, true, false, true)
+}
+
+// IsSubset returns true if every item of s is also in other.
+func (s *Set) IsSubset(other *Set) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	x, y := s.tree.IterAscend(), other.tree.IterAscend()
+	b := y()
+	for a := x(); a != nil; a = x() {
+		for b != nil && s.tree.less(b, a) {
+			b = y()
+		}
+		if b == nil || s.tree.less(a, b) {
+			return false
+		}
+		b = y()
+	}
+	return true
+}
+
+// Equal returns true if both sets hold items of the same order.
+func (s *Set) Equal(other *Set) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
+
+// merge walks both sets in order and keeps the items that are only in s,
+// in both sets, or only in other, as selected by onlyS, both and onlyOther.
+func (s *Set) merge(other *Set, onlyS, both, onlyOther bool) *Set {
+	var items []Item
+	x, y := s.tree.IterAscend(), other.tree.IterAscend()
+	a, b := x(), y()
+	for a != nil && b != nil {
+		switch c := s.tree.compare(a, b); {
+		case c < 0:
+			if onlyS {
+				items = append(items, a)
+			}
+			a = x()
+		case c > 0:
+			if onlyOther {
+				items = append(items, b)
+			}
+			b = y()
+		default:
+			if both {
+				items = append(items, a)
+			}
+			a, b = x(), y()
+		}
+	}
+	for ; onlyS && a != nil; a = x() {
+		items = append(items, a)
+	}
+	for ; onlyOther && b != nil; b = y() {
+		items = append(items, b)
+	}
+	r := &Set{tree: s.tree.empty()}
+	r.tree.root = r.tree.build(items)
+	r.tree.count = len(items)
+	return r
+}
@@ -0,0 +1,83 @@
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newIntSet(items ...int) *Set {
+	s := NewSet()
+	for _, i := range items {
+		s.Insert(Int(i))
+	}
+	return s
+}
+
+func TestSetOperations(t *testing.T) {
+	a := newIntSet(1, 2, 3, 4, 5, 8)
+	b := newIntSet(4, 5, 6, 7, 8, 9)
+	cases := []struct {
+		name     string
+		s        *Set
+		expected []Item
+	}{
+		{"union", a.Union(b), []Item{Int(1), Int(2), Int(3), Int(4), Int(5), Int(6), Int(7), Int(8), Int(9)}},
+		{"intersect", a.Intersect(b), []Item{Int(4), Int(5), Int(8)}},
+		{"difference", a.Difference(b), []Item{Int(1), Int(2), Int(3)}},
+		{"symmetric difference", a.SymmetricDifference(b), []Item{Int(1), Int(2), Int(3), Int(6), Int(7), Int(9)}},
+		{"empty union", NewSet().Union(NewSet()), nil},
+		{"empty intersect", a.Intersect(NewSet()), nil},
+	}
+	for _, c := range cases {
+		checkLLRB(t, c.s.tree)
+		if got := collect(c.s.Ascend); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.expected, got)
+		}
+		if c.s.Len() != len(c.expected) {
+			t.Errorf("%s: expected len %d but got %d", c.name, len(c.expected), c.s.Len())
+		}
+	}
+	if a.Len() != 6 || b.Len() != 6 {
+		t.Errorf("operands were modified")
+	}
+}
+
+func TestSetSubsetEqual(t *testing.T) {
+	a := newIntSet(2, 4, 6)
+	b := newIntSet(1, 2, 3, 4, 5, 6)
+	if !a.IsSubset(b) || b.IsSubset(a) {
+		t.Errorf("bad subset")
+	}
+	if !NewSet().IsSubset(a) || !a.IsSubset(a) {
+		t.Errorf("bad trivial subset")
+	}
+	if newIntSet(2, 4, 7).IsSubset(b) {
+		t.Errorf("bad subset with missing item")
+	}
+	if !a.Equal(newIntSet(6, 4, 2)) || a.Equal(b) || a.Equal(newIntSet(2, 4, 5)) {
+		t.Errorf("bad equality")
+	}
+}
+
+func TestSetWith(t *testing.T) {
+	a, b := NewSetWith(lessInt), NewSetWith(lessInt)
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			a.Insert(i)
+		}
+		if i%3 == 0 {
+			b.Insert(i)
+		}
+	}
+	u := a.Union(b)
+	checkLLRB(t, u.tree)
+	if u.Len() != 500+334-167 {
+		t.Errorf("bad union len %d", u.Len())
+	}
+	if i := a.Intersect(b); i.Len() != 167 || !i.IsSubset(a) || !i.IsSubset(b) {
+		t.Errorf("bad intersection")
+	}
+	if !a.Difference(b).Union(a.Intersect(b)).Equal(a) {
+		t.Errorf("difference and intersection do not add up")
+	}
+}
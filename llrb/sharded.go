@@ -0,0 +1,260 @@
+package llrb
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Sharded is an ordered collection of distinct items that may be used from several
+// goroutines at once. It divides the key space into ranges, each held by its own
+// LLRB shard with its own lock, so that writers working on different ranges
+// proceed in parallel.
+//
+// Sharded aims for an equal number of items in every shard. As the items skew
+// towards some ranges, the shard boundaries are moved: whenever an insertion makes
+// a shard noticeably larger than its share, all shards are joined and split again
+// at evenly spaced ranks, which takes O(N log n) time for N shards holding n items
+// thanks to Join and Split. Rebalance does the same on demand.
+type Sharded struct {
+	count  int64        // Number of items in all shards; accessed atomically
+	n      int          // Number of shards to aim for
+	order  *LLRB        // An empty tree, whose ordering and settings the shards share
+	layout sync.RWMutex // Held for writing while shards and bounds change
+	shards []*shard
+	bounds []Item // Shard i holds the items in [bounds[i-1], bounds[i])
+}
+
+type shard struct {
+	mu   sync.RWMutex
+	tree *LLRB
+}
+
+// minRebalance is the number of items by which a shard must exceed its share
+// before an insertion triggers a rebalance, so that small trees are not
+// rebalanced over and over.
+const minRebalance = 64
+
+// NewSharded allocates a new tree with up to n shards, whose items implement Lesser.
+func NewSharded(n int) *Sharded {
+	return newSharded(n, New())
+}
+
+// NewShardedWith allocates a new tree with up to n shards, which orders its items with less.
+func NewShardedWith(n int, less LessFunc) *Sharded {
+	return newSharded(n, NewWith(less))
+}
+
+func newSharded(n int, order *LLRB) *Sharded {
+	if n < 1 {
+		panic("shard count")
+	}
+	order.EnableRank() // Rebalancing selects items by rank
+	return &Sharded{n: n, order: order, shards: []*shard{{tree: order.empty()}}}
+}
+
+// Len returns the number of items in the tree.
+func (s *Sharded) Len() int { return int(atomic.LoadInt64(&s.count)) }
+
+// Shards returns the current number of shards, which is less than the number
+// requested while the tree holds fewer items than that.
+func (s *Sharded) Shards() int {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	return len(s.shards)
+}
+
+// route returns the index of the shard whose range contains key.
+func (s *Sharded) route(key Item) int {
+	return sort.Search(len(s.bounds), func(i int) bool { return s.order.less(key, s.bounds[i]) })
+}
+
+// Has returns true if the tree contains an item whose order is the same as that of key.
+func (s *Sharded) Has(key Item) bool {
+	return s.Get(key) != nil
+}
+
+// Get retrieves an item from the tree whose order is the same as that of key.
+func (s *Sharded) Get(key Item) Item {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	sh := s.shards[s.route(key)]
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	return sh.tree.Get(key)
+}
+
+// ReplaceOrInsert inserts item into the tree. If an existing
+// item has the same order, it is removed from the tree and returned.
+func (s *Sharded) ReplaceOrInsert(item Item) Item {
+	replaced, skewed := s.replaceOrInsert(item)
+	if skewed {
+		s.layout.Lock()
+		if s.skewed() {
+			s.rebalance()
+		}
+		s.layout.Unlock()
+	}
+	return replaced
+}
+
+// replaceOrInsert inserts item, and reports whether its shard has grown beyond its share.
+func (s *Sharded) replaceOrInsert(item Item) (Item, bool) {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	sh := s.shards[s.route(item)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	replaced := sh.tree.ReplaceOrInsert(item)
+	if replaced == nil {
+		atomic.AddInt64(&s.count, 1)
+	}
+	return replaced, sh.tree.Len() > s.limit()
+}
+
+// Delete deletes the item from the tree whose order is the same as that of key.
+// The deleted item is returned, otherwise nil is returned.
+func (s *Sharded) Delete(key Item) Item {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	sh := s.shards[s.route(key)]
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	deleted := sh.tree.Delete(key)
+	if deleted != nil {
+		atomic.AddInt64(&s.count, -1)
+	}
+	return deleted
+}
+
+// Min returns the minimum item in the tree.
+func (s *Sharded) Min() Item {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		min := sh.tree.Min()
+		sh.mu.RUnlock()
+		if min != nil {
+			return min
+		}
+	}
+	return nil
+}
+
+// Max returns the maximum item in the tree.
+func (s *Sharded) Max() Item {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		sh := s.shards[i]
+		sh.mu.RLock()
+		max := sh.tree.Max()
+		sh.mu.RUnlock()
+		if max != nil {
+			return max
+		}
+	}
+	return nil
+}
+
+// Ascend will call iterator once for each item in ascending order.
+// It will stop whenever the iterator returns false. See AscendRange.
+func (s *Sharded) Ascend(iterator ItemIterator) {
+	s.AscendRange(Inf(-1), Inf(1), iterator)
+}
+
+// AscendRange will call iterator once for each item greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+//
+// The traversal visits snapshots of the shards covering the range, all taken at
+// once when it starts, so it sees the tree as it was at a single point in time.
+// It holds no locks while calling iterator, which may therefore call the methods
+// of s.
+func (s *Sharded) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	for _, p := range s.snapshots(greaterOrEqual, lessThan) {
+		stopped := false
+		p.AscendRange(greaterOrEqual, lessThan, func(i Item) bool {
+			stopped = !iterator(i)
+			return !stopped
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// snapshots returns snapshots of the shards whose ranges intersect [lo, hi), in order.
+// It holds the read locks of all those shards at once, taken in order, so that no
+// modification can fall between two of the snapshots.
+func (s *Sharded) snapshots(lo, hi Item) []*Persistent {
+	s.layout.RLock()
+	defer s.layout.RUnlock()
+	first, last := s.route(lo), s.route(hi)
+	if last < first {
+		return nil
+	}
+	shards := s.shards[first : last+1]
+	for _, sh := range shards {
+		sh.mu.RLock()
+	}
+	ps := make([]*Persistent, len(shards))
+	for i, sh := range shards {
+		ps[i] = sh.tree.Snapshot()
+	}
+	for _, sh := range shards {
+		sh.mu.RUnlock()
+	}
+	return ps
+}
+
+// Rebalance moves the shard boundaries so that every shard holds the same number
+// of items, give or take one. It blocks all other operations while it runs.
+func (s *Sharded) Rebalance() {
+	s.layout.Lock()
+	defer s.layout.Unlock()
+	s.rebalance()
+}
+
+// limit returns the size beyond which a shard triggers a rebalance.
+func (s *Sharded) limit() int {
+	return s.Len()/s.n*3/2 + minRebalance
+}
+
+// skewed returns true if some shard is larger than limit. The caller must hold the layout lock.
+func (s *Sharded) skewed() bool {
+	for _, sh := range s.shards {
+		if sh.tree.Len() > s.limit() {
+			return true
+		}
+	}
+	return false
+}
+
+// rebalance joins all shards and splits the result into up to n shards of equal
+// size. The caller must hold the layout lock for writing.
+func (s *Sharded) rebalance() {
+	all := s.order.empty()
+	for _, sh := range s.shards {
+		all = Join(all, sh.tree)
+	}
+	n := s.n
+	if all.Len() < n {
+		n = all.Len()
+	}
+	if n < 1 {
+		n = 1
+	}
+	shards := make([]*shard, 0, n)
+	bounds := make([]Item, 0, n-1)
+	for k := n; k > 1; k-- {
+		// Give the next shard its share of the items that remain for k shards
+		bound := all.Select(all.Len() / k)
+		left, right := all.Split(bound)
+		shards = append(shards, &shard{tree: left})
+		bounds = append(bounds, bound)
+		all = right
+	}
+	s.shards, s.bounds = append(shards, &shard{tree: all}), bounds
+}
@@ -0,0 +1,199 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// checkSharded checks every shard, and that each holds only the items in its range.
+func checkSharded(t *testing.T, s *Sharded) {
+	n := 0
+	for i, sh := range s.shards {
+		checkLLRB(t, sh.tree)
+		n += sh.tree.Len()
+		sh.tree.Ascend(func(item Item) bool {
+			if i > 0 && s.order.less(item, s.bounds[i-1]) || i < len(s.bounds) && !s.order.less(item, s.bounds[i]) {
+				t.Errorf("item %v is outside of shard %d", item, i)
+			}
+			return true
+		})
+	}
+	if len(s.bounds) != len(s.shards)-1 {
+		t.Errorf("expected %d bounds for %d shards", len(s.shards)-1, len(s.bounds))
+	}
+	if n != s.Len() {
+		t.Errorf("expected %d items in the shards but got %d", s.Len(), n)
+	}
+}
+
+func TestSharded(t *testing.T) {
+	s := NewSharded(8)
+	ref := New()
+	if s.Min() != nil || s.Max() != nil || s.Shards() != 1 {
+		t.Errorf("expecting an empty tree with one shard")
+	}
+	// Ascending insertions skew towards the last shard
+	for i := 0; i < 3000; i++ {
+		s.ReplaceOrInsert(Int(i))
+		ref.ReplaceOrInsert(Int(i))
+		if i%3 == 0 {
+			k := Int(rand.Intn(i + 1))
+			if (s.Delete(k) == nil) != (ref.Delete(k) == nil) {
+				t.Fatalf("unexpected result deleting %v", k)
+			}
+		}
+	}
+	checkSharded(t, s)
+	if s.Shards() != 8 {
+		t.Errorf("expected 8 shards but got %d", s.Shards())
+	}
+	for _, sh := range s.shards {
+		if sh.tree.Len() > s.limit() {
+			t.Errorf("expected no shard beyond %d items, got %d", s.limit(), sh.tree.Len())
+		}
+	}
+	if s.Len() != ref.Len() || s.Min() != ref.Min() || s.Max() != ref.Max() {
+		t.Errorf("expected %d items from %v to %v", ref.Len(), ref.Min(), ref.Max())
+	}
+	for i := 0; i < 100; i++ {
+		lo, hi := Int(rand.Intn(3100)-50), Int(rand.Intn(3100)-50)
+		got, expected := []Item{}, []Item{}
+		s.AscendRange(lo, hi, func(i Item) bool {
+			got = append(got, i)
+			return true
+		})
+		ref.AscendRange(lo, hi, func(i Item) bool {
+			expected = append(expected, i)
+			return true
+		})
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("range [%v, %v): expected %v but got %v", lo, hi, expected, got)
+		}
+		if k := Int(rand.Intn(3000)); s.Has(k) != ref.Has(k) || s.Get(k) != ref.Get(k) {
+			t.Fatalf("expected the same lookup of %v", k)
+		}
+	}
+	s.Rebalance()
+	checkSharded(t, s)
+	for _, sh := range s.shards {
+		if d := sh.tree.Len() - s.Len()/8; d < 0 || d > 1 {
+			t.Errorf("expected %d or %d items in each shard, got %d", s.Len()/8, s.Len()/8+1, sh.tree.Len())
+		}
+	}
+	// The traversal may modify the tree, and stops across shards
+	n := 0
+	s.Ascend(func(i Item) bool {
+		s.Delete(i)
+		n++
+		return n < 1000
+	})
+	if n != 1000 || s.Len() != ref.Len()-1000 {
+		t.Errorf("expected 1000 visits and deletions, got %d and %d", n, ref.Len()-s.Len())
+	}
+	checkSharded(t, s)
+}
+
+func TestShardedFew(t *testing.T) {
+	s := NewShardedWith(4, lessInt)
+	s.ReplaceOrInsert(2)
+	s.ReplaceOrInsert(1)
+	s.Rebalance()
+	checkSharded(t, s)
+	if s.Shards() != 2 || s.Min() != 1 || s.Max() != 2 {
+		t.Errorf("expected two shards holding 1 and 2")
+	}
+	s.Delete(1)
+	s.Delete(2)
+	s.Rebalance()
+	checkSharded(t, s)
+	if s.Shards() != 1 || s.Len() != 0 || s.Min() != nil {
+		t.Errorf("expected one empty shard")
+	}
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	s := NewSharded(4)
+	const writers, n = 4, 1000
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				s.ReplaceOrInsert(Int(i*writers + w))
+				if i%2 == 1 {
+					s.Delete(Int(i*writers + w))
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				var prev Item
+				s.AscendRange(Int(n), Int(3*n), func(i Item) bool {
+					if prev != nil && prev.(Int) >= i.(Int) {
+						t.Errorf("out of order: %v after %v", i, prev)
+					}
+					prev = i
+					return true
+				})
+				s.Get(Int(n))
+				s.Min()
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	checkSharded(t, s)
+	if s.Len() != writers*n/2 {
+		t.Errorf("expected %d items but got %d", writers*n/2, s.Len())
+	}
+}
+
+func TestShardedConsistentSnapshots(t *testing.T) {
+	s := NewSharded(8)
+	const n, high = 5000, 1000000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < n; i++ {
+			s.ReplaceOrInsert(Int(i))
+			s.ReplaceOrInsert(Int(high + i))
+		}
+	}()
+	// Every low item is inserted before the matching high one, which usually lands
+	// in a later shard, so no point in time sees more high items than low ones.
+	for {
+		select {
+		case <-done:
+			checkSharded(t, s)
+			return
+		default:
+		}
+		lows, highs := 0, 0
+		s.Ascend(func(i Item) bool {
+			if i.(Int) < high {
+				lows++
+			} else {
+				highs++
+			}
+			return true
+		})
+		if highs > lows {
+			t.Fatalf("saw %d high items but only %d low ones", highs, lows)
+		}
+	}
+}
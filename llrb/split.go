@@ -0,0 +1,252 @@
+package llrb
+
+// Split moves the elements of t into two new trees, left holding the elements less
+// than key and right holding the others, and leaves t empty. The new trees are
+// ordered and augmented like t.
+//
+// Split takes O(log n) time in a tree with EnableRank. Otherwise the new trees
+// cannot learn their sizes from their roots, and counting the elements of the
+// smaller one takes additional time linear in its size.
+func (t *LLRB) Split(key Item) (left, right *LLRB) {
+	l, _, r, _ := t.split(t.root, blackHeight(t.root), key)
+	left, right = t.empty(), t.empty()
+	left.root, right.root = l, r
+	if t.ranked {
+		left.count = size(l)
+	} else {
+		left.count = countLeft(l, r, t.count)
+	}
+	right.count = t.count - left.count
+	t.root, t.count = nil, 0
+	t.mods++
+	return left, right
+}
+
+// Join returns a tree holding the elements of left followed by those of right, in
+// O(log n) time, and leaves both left and right empty. No element of right may be
+// less than an element of left. The result is ordered and augmented like left. If
+// right is not ranked or augmented while left is, Join first enables rank or sets
+// the augmentation of left on right, which takes time linear in its size. Trees
+// that are both augmented must be augmented with the same function.
+func Join(left, right *LLRB) *LLRB {
+	if left.root != nil && right.root != nil && left.less(right.Min(), left.Max()) {
+		panic("joining overlapping trees")
+	}
+	if left.ranked && !right.ranked {
+		right.EnableRank()
+	}
+	if left.augment != nil && right.augment == nil {
+		right.SetAugment(left.augment)
+	}
+	t := left.empty()
+	t.count = left.count + right.count
+	t.root, _ = t.concat(left.root, blackHeight(left.root), right.root, blackHeight(right.root))
+	left.root, left.count = nil, 0
+	right.root, right.count = nil, 0
+	left.mods++
+	right.mods++
+	return t
+}
+
+// empty returns an empty tree ordered and augmented like t. It has a token of its
+// own, so it copies any node of t before modifying it, since the node may also be
+// shared with a snapshot or a clone of t.
+func (t *LLRB) empty() *LLRB {
+	return &LLRB{lessFunc: t.lessFunc, ranked: t.ranked, augment: t.augment, cow: new(cowToken)}
+}
+
+// blackHeight returns the number of black nodes on any path from h down to a leaf.
+func blackHeight(h *Node) int {
+	bh := 0
+	for ; h != nil; h = h.Left {
+		if h.Black {
+			bh++
+		}
+	}
+	return bh
+}
+
+func countNodes(h *Node) int {
+	if h == nil {
+		return 0
+	}
+	return countNodes(h.Left) + 1 + countNodes(h.Right)
+}
+
+// countLeft returns the number of nodes under l, given that l and r hold total
+// nodes together. It counts the nodes of both in turns until either runs out,
+// which takes time linear in the size of the smaller one.
+func countLeft(l, r *Node, total int) int {
+	var lc, rc nodeCounter
+	lc.push(l)
+	rc.push(r)
+	for {
+		if !lc.next() {
+			return lc.n
+		}
+		if !rc.next() {
+			return total - rc.n
+		}
+	}
+}
+
+// nodeCounter counts the nodes of a subtree one at a time.
+type nodeCounter struct {
+	stack []*Node // The roots of the subtrees yet to be counted
+	n     int
+}
+
+func (c *nodeCounter) push(h *Node) {
+	if h != nil {
+		c.stack = append(c.stack, h)
+	}
+}
+
+// next counts one more node, and returns false if there are none left.
+func (c *nodeCounter) next() bool {
+	if len(c.stack) == 0 {
+		return false
+	}
+	h := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	c.push(h.Left)
+	c.push(h.Right)
+	c.n++
+	return true
+}
+
+// blacken colors h black, and returns it along with its resulting black height,
+// given its black height bh before.
+func (t *LLRB) blacken(h *Node, bh int) (*Node, int) {
+	if !isRed(h) {
+		return h, bh
+	}
+	h = t.mutable(h)
+	h.Black = true
+	return h, bh + 1
+}
+
+// split divides the subtree at h, of black height bh, into black-rooted subtrees
+// holding the items less than key and the others, and returns them with their
+// black heights.
+func (t *LLRB) split(h *Node, bh int, key Item) (l *Node, lbh int, r *Node, rbh int) {
+	return t.splitBy(h, bh, func(h *Node) bool { return t.less(h.Item, key) })
+}
+
+// splitBy is like split, except that the items of the left subtree are those of the
+// nodes for which goesLeft is true. No node for which it is true may follow one
+// for which it is false. splitBy calls goesLeft only on the nodes on a single
+// path down from h, before modifying them.
+func (t *LLRB) splitBy(h *Node, bh int, goesLeft func(h *Node) bool) (l *Node, lbh int, r *Node, rbh int) {
+	if h == nil {
+		return nil, 0, nil, 0
+	}
+	cbh := bh // black height of the children of @h
+	if h.Black {
+		cbh--
+	}
+	if goesLeft(h) {
+		left, leftbh := t.blacken(h.Left, cbh)
+		l, lbh, r, rbh = t.splitBy(h.Right, cbh, goesLeft)
+		l, lbh = t.joinBlack(left, leftbh, h.Item, l, lbh)
+		return l, lbh, r, rbh
+	}
+	right, rightbh := t.blacken(h.Right, cbh)
+	l, lbh, r, rbh = t.splitBy(h.Left, cbh, goesLeft)
+	r, rbh = t.joinBlack(r, rbh, h.Item, right, rightbh)
+	return l, lbh, r, rbh
+}
+
+// joinBlack is like join, except that it returns a black root along with its black height.
+func (t *LLRB) joinBlack(l *Node, lbh int, k Item, r *Node, rbh int) (*Node, int) {
+	bh := lbh
+	if rbh > bh {
+		bh = rbh
+	}
+	return t.blacken(t.join(l, lbh, k, r, rbh), bh)
+}
+
+// join returns the root of a subtree holding the items of l, then k, then the items
+// of r. The subtrees l and r must be black-rooted, with black heights lbh and rbh.
+// The resulting root may be red.
+func (t *LLRB) join(l *Node, lbh int, k Item, r *Node, rbh int) *Node {
+	switch {
+	case lbh > rbh:
+		return t.joinRight(l, lbh, k, r, rbh)
+	case lbh < rbh:
+		return t.joinLeft(l, lbh, k, r, rbh)
+	}
+	h := t.newNode(k)
+	h.Left, h.Right = l, r
+	t.update(h)
+	return h
+}
+
+// joinRight descends the right spine of h, of black height hbh, to the black node
+// of black height rbh, and joins it with k and r there. Red links introduced on
+// the way back up are fixed as in insertion.
+func (t *LLRB) joinRight(h *Node, hbh int, k Item, r *Node, rbh int) *Node {
+	if hbh == rbh && !isRed(h) {
+		return t.join(h, hbh, k, r, rbh)
+	}
+	h = t.mutable(h)
+	if h.Black {
+		hbh--
+	}
+	h.Right = t.joinRight(h.Right, hbh, k, r, rbh)
+	return t.walkUpRot23(h)
+}
+
+// joinLeft is the mirror image of joinRight, descending the left spine of h.
+func (t *LLRB) joinLeft(l *Node, lbh int, k Item, h *Node, hbh int) *Node {
+	if hbh == lbh && !isRed(h) {
+		return t.join(l, lbh, k, h, hbh)
+	}
+	h = t.mutable(h)
+	if h.Black {
+		hbh--
+	}
+	h.Left = t.joinLeft(l, lbh, k, h.Left, hbh)
+	return t.walkUpRot23(h)
+}
+
+// concat is like joinBlack, but without an item between l and r.
+func (t *LLRB) concat(l *Node, lbh int, r *Node, rbh int) (*Node, int) {
+	switch {
+	case r == nil:
+		return l, lbh
+	case l == nil:
+		return r, rbh
+	}
+	r, k := t.deleteMin(r)
+	r, _ = t.blacken(r, 0)
+	return t.joinBlack(l, lbh, k, r, blackHeight(r))
+}
+
+// DeleteRange deletes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns how many were deleted. It takes O(log n + k) time to delete
+// k elements, or O(log n) time in a tree with EnableRank.
+func (t *LLRB) DeleteRange(greaterOrEqual, lessThan Item) int {
+	return t.ExtractRange(greaterOrEqual, lessThan).Len()
+}
+
+// ExtractRange removes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns them as a new tree ordered and augmented like t. It takes
+// O(log n + k) time to extract k elements, or O(log n) time in a tree with EnableRank.
+func (t *LLRB) ExtractRange(greaterOrEqual, lessThan Item) *LLRB {
+	x := t.empty()
+	if !t.less(greaterOrEqual, lessThan) {
+		return x
+	}
+	t.mods++
+	l, lbh, r, rbh := t.split(t.root, blackHeight(t.root), greaterOrEqual)
+	x.root, _, r, rbh = t.split(r, rbh, lessThan)
+	if t.ranked {
+		x.count = size(x.root)
+	} else {
+		x.count = countNodes(x.root)
+	}
+	t.root, _ = t.concat(l, lbh, r, rbh)
+	t.count -= x.count
+	return x
+}
@@ -0,0 +1,189 @@
+package llrb
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func intTree(ranked bool, keys ...int) *LLRB {
+	tree := New()
+	if ranked {
+		tree.EnableRank()
+	}
+	for _, k := range keys {
+		tree.InsertNoReplace(Int(k))
+	}
+	return tree
+}
+
+func TestSplitJoin(t *testing.T) {
+	for trial := 0; trial < 200; trial++ {
+		n := rand.Intn(300)
+		tree := intTree(trial%2 == 0, rand.Perm(n)...)
+		tree.SetAugment(sumAugment)
+		items := collect(tree.Ascend)
+		key := rand.Intn(n+2) - 1
+		left, right := tree.Split(Int(key))
+		if tree.Len() != 0 || tree.Root() != nil {
+			t.Fatalf("split left the tree non-empty")
+		}
+		checkLLRB(t, left)
+		checkLLRB(t, right)
+		checkSummaries(t, left, left.root)
+		checkSummaries(t, right, right.root)
+		k := key
+		if k < 0 {
+			k = 0
+		} else if k > n {
+			k = n
+		}
+		if got := append(collect(left.Ascend), collect(right.Ascend)...); !reflect.DeepEqual(got, items) || left.Len() != k || right.Len() != n-k {
+			t.Fatalf("split of %d items at %d: got %d and %d items", n, key, left.Len(), right.Len())
+		}
+		joined := Join(left, right)
+		checkLLRB(t, joined)
+		checkSummaries(t, joined, joined.root)
+		if got := collect(joined.Ascend); !reflect.DeepEqual(got, items) {
+			t.Fatalf("join does not restore the split tree")
+		}
+		if left.Len() != 0 || right.Len() != 0 {
+			t.Fatalf("join left its arguments non-empty")
+		}
+		mutateRandomly(joined, 50, n+1)
+		checkLLRB(t, joined)
+	}
+}
+
+func TestJoinUneven(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 0}, {0, 10}, {10, 0}, {1, 1000}, {1000, 1}, {37, 5000}, {5000, 37}} {
+		var l, r []int
+		for i := 0; i < sizes[0]; i++ {
+			l = append(l, i)
+		}
+		for i := 0; i < sizes[1]; i++ {
+			r = append(r, sizes[0]+i)
+		}
+		joined := Join(intTree(true, l...), intTree(false, r...))
+		checkLLRB(t, joined)
+		if joined.Len() != sizes[0]+sizes[1] {
+			t.Fatalf("expecting len %d, got %d", sizes[0]+sizes[1], joined.Len())
+		}
+		for i := 0; i < joined.Len(); i++ {
+			if joined.Select(i) != Int(i) {
+				t.Fatalf("expecting %d at %d", i, i)
+			}
+		}
+	}
+}
+
+func TestJoinAugmentMismatch(t *testing.T) {
+	left, right := intTree(false, 1, 2, 3), intTree(false, 4, 5, 6)
+	left.SetAugment(sumAugment)
+	joined := Join(left, right)
+	checkLLRB(t, joined)
+	checkSummaries(t, joined, joined.root)
+	if s := summary(joined.Root()); s != 21 {
+		t.Errorf("expecting sum 21, got %v", s)
+	}
+}
+
+func TestSplitShared(t *testing.T) {
+	tree := intTree(false, rand.Perm(500)...)
+	clone := tree.Clone()
+	items := collect(clone.Ascend)
+	left, right := tree.Split(Int(250))
+	checkLLRB(t, left)
+	checkLLRB(t, right)
+	if left.Len() != 250 || right.Len() != 250 {
+		t.Errorf("expecting 250 and 250 items, got %d and %d", left.Len(), right.Len())
+	}
+	if got := collect(clone.Ascend); !reflect.DeepEqual(got, items) {
+		t.Errorf("splitting a tree changed its clone")
+	}
+}
+
+func TestJoinOverlapping(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic")
+		}
+	}()
+	Join(intTree(false, 1, 5), intTree(false, 3, 7))
+}
+
+func TestExtractRange(t *testing.T) {
+	for trial := 0; trial < 200; trial++ {
+		n := rand.Intn(300)
+		tree := intTree(trial%2 == 0, rand.Perm(n)...)
+		for _, i := range rand.Perm(n / 4) {
+			tree.InsertNoReplace(Int(i))
+		}
+		clone := tree.Clone()
+		lo, hi := Int(rand.Intn(n+2)-1), Int(rand.Intn(n+2)-1)
+		var kept, extracted []Item
+		for _, i := range collect(tree.Ascend) {
+			if lo <= i.(Int) && i.(Int) < hi {
+				extracted = append(extracted, i)
+			} else {
+				kept = append(kept, i)
+			}
+		}
+		var x *LLRB
+		if trial%3 == 0 {
+			if d := tree.DeleteRange(lo, hi); d != len(extracted) {
+				t.Fatalf("deleting [%d, %d): expecting %d deleted, got %d", lo, hi, len(extracted), d)
+			}
+		} else {
+			x = tree.ExtractRange(lo, hi)
+			checkLLRB(t, x)
+			if got := collect(x.Ascend); !reflect.DeepEqual(got, extracted) {
+				t.Fatalf("extracting [%d, %d): expected %v but got %v", lo, hi, extracted, got)
+			}
+		}
+		checkLLRB(t, tree)
+		if got := collect(tree.Ascend); !reflect.DeepEqual(got, kept) {
+			t.Fatalf("extracting [%d, %d): expected %v to remain, got %v", lo, hi, kept, got)
+		}
+		if clone.Len() != n+n/4 || len(collect(clone.Ascend)) != n+n/4 {
+			t.Fatalf("extracting a range changed a clone")
+		}
+		mutateRandomly(tree, 20, n+1)
+		checkLLRB(t, tree)
+	}
+}
+
+func TestDeleteRangeInf(t *testing.T) {
+	tree := intTree(false, rand.Perm(100)...)
+	if d := tree.DeleteRange(Inf(-1), Int(10)); d != 10 {
+		t.Errorf("expecting 10 deleted, got %d", d)
+	}
+	if d := tree.DeleteRange(Int(90), Inf(1)); d != 10 {
+		t.Errorf("expecting 10 deleted, got %d", d)
+	}
+	if d := tree.DeleteRange(Int(50), Int(50)); d != 0 {
+		t.Errorf("deleted from an empty range")
+	}
+	if tree.Min() != Int(10) || tree.Max() != Int(89) || tree.Len() != 80 {
+		t.Errorf("bad tree after deletions")
+	}
+	if d := tree.DeleteRange(Inf(-1), Inf(1)); d != 80 || tree.Len() != 0 || tree.Root() != nil {
+		t.Errorf("expecting the tree to be emptied")
+	}
+	checkLLRB(t, tree)
+}
+
+func TestJoinSnapshot(t *testing.T) {
+	right := intTree(false, rand.Perm(100)...)
+	p := right.Snapshot()
+	before := collect(p.Ascend)
+	tree := Join(New(), right)
+	for i := 100; i < 200; i++ {
+		tree.ReplaceOrInsert(Int(i))
+		tree.Delete(Int(i - 100))
+	}
+	checkLLRB(t, &p.tree)
+	if after := collect(p.Ascend); !reflect.DeepEqual(after, before) {
+		t.Errorf("expected the snapshot to be unaffected by the join")
+	}
+}
@@ -0,0 +1,469 @@
+package llrb
+
+import "sync"
+
+// Sync is an LLRB that may be used from several goroutines at once. Its methods
+// are those of LLRB, each guarded by a sync.RWMutex: queries and traversals run
+// under the read lock, so they may proceed in parallel, and modifications run
+// under the write lock.
+//
+// A traversal holds the read lock until it returns, so its iterator sees a
+// consistent tree, but it must not call the methods of the same Sync: writes
+// would deadlock, and so could reads, if a writer is waiting for the lock. To
+// iterate without holding the lock, or to modify the tree while visiting it,
+// take a Snapshot and traverse that instead. Compound operations that must be
+// atomic can be run with View and Update.
+//
+// IterAscend, Cursor and the sequences of seq.go outlive the calls that create
+// them, so they cannot hold the lock. They traverse a snapshot of the tree
+// instead, taken when they are created, or for a sequence, when its iteration
+// begins, and are not affected by later modifications.
+//
+// Root, SetRoot and Txn are left out, since the nodes and transactions they deal
+// in would escape the lock. Use View, Update or Snapshot instead.
+type Sync struct {
+	mu   sync.RWMutex
+	tree *LLRB
+}
+
+// NewSync allocates a new concurrency-safe tree whose items implement Lesser.
+func NewSync() *Sync {
+	return &Sync{tree: New()}
+}
+
+// NewSyncWith allocates a new concurrency-safe tree that orders its items with less.
+func NewSyncWith(less LessFunc) *Sync {
+	return &Sync{tree: NewWith(less)}
+}
+
+// View calls f with the underlying tree under the read lock. F must not modify
+// the tree, or retain it after returning.
+func (s *Sync) View(f func(t *LLRB)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f(s.tree)
+}
+
+// Update calls f with the underlying tree under the write lock. F must not
+// retain the tree after returning.
+func (s *Sync) Update(f func(t *LLRB)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.tree)
+}
+
+// Snapshot returns the current contents of the tree as a persistent tree, which
+// may be read and traversed without any locking. See LLRB.Snapshot.
+func (s *Sync) Snapshot() *Persistent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Snapshot()
+}
+
+// Clone returns a copy of the tree, which is not synchronized. See LLRB.Clone.
+func (s *Sync) Clone() *LLRB {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Clone()
+}
+
+// snapshot returns a copy of the tree that is never modified, for traversals that
+// cannot hold the read lock.
+func (s *Sync) snapshot() *LLRB {
+	return &s.Snapshot().tree
+}
+
+// Queries
+
+// Len returns the number of nodes in the tree.
+func (s *Sync) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Len()
+}
+
+// Has returns true if the tree contains an element whose order is the same as that of key.
+func (s *Sync) Has(key Item) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Has(key)
+}
+
+// Get retrieves an element from the tree whose order is the same as that of key.
+func (s *Sync) Get(key Item) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Get(key)
+}
+
+// Min returns the minimum element in the tree.
+func (s *Sync) Min() Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Min()
+}
+
+// Max returns the maximum element in the tree.
+func (s *Sync) Max() Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Max()
+}
+
+// Floor returns the greatest element in the tree that is less than or equal to key.
+func (s *Sync) Floor(key Item) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Floor(key)
+}
+
+// Ceiling returns the least element in the tree that is greater than or equal to key.
+func (s *Sync) Ceiling(key Item) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Ceiling(key)
+}
+
+// Lower returns the greatest element in the tree that is strictly less than key.
+func (s *Sync) Lower(key Item) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Lower(key)
+}
+
+// Higher returns the least element in the tree that is strictly greater than key.
+func (s *Sync) Higher(key Item) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Higher(key)
+}
+
+// Count returns the number of items in the tree whose order is the same as that of key.
+func (s *Sync) Count(key Item) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Count(key)
+}
+
+// GetAll returns the items in the tree whose order is the same as that of key,
+// in insertion order.
+func (s *Sync) GetAll(key Item) []Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.GetAll(key)
+}
+
+// Ranked returns true if the tree maintains subtree sizes.
+func (s *Sync) Ranked() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Ranked()
+}
+
+// Rank returns the number of elements in the tree that are less than key.
+// See LLRB.Rank.
+func (s *Sync) Rank(key Item) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Rank(key)
+}
+
+// Select returns the element at 0-based position i in ascending order.
+// See LLRB.Select.
+func (s *Sync) Select(i int) Item {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Select(i)
+}
+
+// CountRange returns the number of elements greater or equal to greaterOrEqual
+// and less than lessThan. See LLRB.CountRange.
+func (s *Sync) CountRange(greaterOrEqual, lessThan Item) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.CountRange(greaterOrEqual, lessThan)
+}
+
+// Summary returns the summary of the whole tree. See LLRB.Summary.
+func (s *Sync) Summary() interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Summary()
+}
+
+// Aggregate returns the summary of the elements greater or equal to greaterOrEqual
+// and less than lessThan. See LLRB.Aggregate.
+func (s *Sync) Aggregate(greaterOrEqual, lessThan Item) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.Aggregate(greaterOrEqual, lessThan)
+}
+
+// GetHeight returns an item in the tree with key @key, and it's height in the tree
+func (s *Sync) GetHeight(key Item) (result Item, depth int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.GetHeight(key)
+}
+
+// HeightStats returns the average and standard deviation of the height
+// of elements in the tree
+func (s *Sync) HeightStats() (avg, stddev float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tree.HeightStats()
+}
+
+// Modifications
+
+// EnableRank makes the tree maintain subtree sizes. See LLRB.EnableRank.
+func (s *Sync) EnableRank() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.EnableRank()
+}
+
+// SetAugment makes the tree maintain subtree summaries. See LLRB.SetAugment.
+func (s *Sync) SetAugment(augment AugmentFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.SetAugment(augment)
+}
+
+func (s *Sync) ReplaceOrInsertBulk(items ...Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.ReplaceOrInsertBulk(items...)
+}
+
+func (s *Sync) InsertNoReplaceBulk(items ...Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.InsertNoReplaceBulk(items...)
+}
+
+// ReplaceOrInsert inserts item into the tree. If an existing
+// element has the same order, it is removed from the tree and returned.
+func (s *Sync) ReplaceOrInsert(item Item) Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.ReplaceOrInsert(item)
+}
+
+// InsertNoReplace inserts item into the tree. If an existing
+// element has the same order, both elements remain in the tree.
+func (s *Sync) InsertNoReplace(item Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tree.InsertNoReplace(item)
+}
+
+// Delete deletes an item from the tree whose key equals key.
+// The deleted item is returned, otherwise nil is returned.
+func (s *Sync) Delete(key Item) Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Delete(key)
+}
+
+// DeleteMin deletes the minimum element in the tree and returns it, or nil if the tree is empty.
+func (s *Sync) DeleteMin() Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteMin()
+}
+
+// DeleteMax deletes the maximum element in the tree and returns it, or nil if the tree is empty.
+func (s *Sync) DeleteMax() Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteMax()
+}
+
+// DeleteAll deletes all items whose order is the same as that of key,
+// and returns how many were deleted.
+func (s *Sync) DeleteAll(key Item) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteAll(key)
+}
+
+// DeleteExact deletes the first item, in insertion order, whose order is the same
+// as that of item and for which eq(item, candidate) is true. See LLRB.DeleteExact.
+func (s *Sync) DeleteExact(item Item, eq func(a, b Item) bool) Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteExact(item, eq)
+}
+
+// DeleteRange deletes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns how many were deleted.
+func (s *Sync) DeleteRange(greaterOrEqual, lessThan Item) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteRange(greaterOrEqual, lessThan)
+}
+
+// ExtractRange removes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns them as a new tree, which is not synchronized.
+func (s *Sync) ExtractRange(greaterOrEqual, lessThan Item) *LLRB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.ExtractRange(greaterOrEqual, lessThan)
+}
+
+// Split moves the elements of the tree into two new trees, which are not
+// synchronized, and leaves the tree empty. See LLRB.Split.
+func (s *Sync) Split(key Item) (left, right *LLRB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Split(key)
+}
+
+// DeleteIf deletes every element for which pred returns true, and returns how many
+// were deleted. Pred is called under the write lock. See DeleteIfInRange.
+func (s *Sync) DeleteIf(pred func(Item) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteIf(pred)
+}
+
+// Retain deletes every element for which pred returns false, and returns how many
+// were deleted. Pred is called under the write lock. See DeleteIfInRange.
+func (s *Sync) Retain(pred func(Item) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.Retain(pred)
+}
+
+// RetainInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns false, and returns how many were deleted.
+// Pred is called under the write lock. See DeleteIfInRange.
+func (s *Sync) RetainInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.RetainInRange(greaterOrEqual, lessThan, pred)
+}
+
+// DeleteIfInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns true, and returns how many were deleted.
+// Pred is called under the write lock, and must not call the methods of s.
+// See LLRB.DeleteIfInRange.
+func (s *Sync) DeleteIfInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.DeleteIfInRange(greaterOrEqual, lessThan, pred)
+}
+
+// LoadSorted replaces the contents of the tree with items. See LLRB.LoadSorted.
+func (s *Sync) LoadSorted(items []Item, allowDuplicates bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tree.LoadSorted(items, allowDuplicates)
+}
+
+// Traversals, which hold the read lock until they return
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (s *Sync) Ascend(iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.Ascend(iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (s *Sync) Descend(iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.Descend(iterator)
+}
+
+// AscendRange will call iterator once for each element greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (s *Sync) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.AscendRange(greaterOrEqual, lessThan, iterator)
+}
+
+// AscendGreaterOrEqual will call iterator once for each element greater or equal to
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (s *Sync) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.AscendGreaterOrEqual(pivot, iterator)
+}
+
+// AscendGreaterThan will call iterator once for each element greater than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (s *Sync) AscendGreaterThan(pivot Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.AscendGreaterThan(pivot, iterator)
+}
+
+// AscendLessThan will call iterator once for each element lower than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (s *Sync) AscendLessThan(pivot Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.AscendLessThan(pivot, iterator)
+}
+
+// AscendEqual will call iterator once for each item whose order is the same as that
+// of key, in insertion order. It will stop whenever the iterator returns false.
+func (s *Sync) AscendEqual(key Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.AscendEqual(key, iterator)
+}
+
+// DescendRange will call iterator once for each element less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (s *Sync) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.DescendRange(lessOrEqual, greaterThan, iterator)
+}
+
+// DescendLessOrEqual will call iterator once for each element less than or equal to
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (s *Sync) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.DescendLessOrEqual(pivot, iterator)
+}
+
+// DescendGreaterThan will call iterator once for each element greater than
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (s *Sync) DescendGreaterThan(pivot Item, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.DescendGreaterThan(pivot, iterator)
+}
+
+// Range will call iterator once for each element between the bounds lo and hi,
+// in the order given by dir. It will stop whenever the iterator returns false.
+func (s *Sync) Range(lo, hi Bound, dir Direction, iterator ItemIterator) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.tree.Range(lo, hi, dir, iterator)
+}
+
+// Traversals of a snapshot, which hold no lock
+
+// IterAscend returns a function that yields the elements of a snapshot of the
+// tree in ascending order, one per call, and nil once they are exhausted.
+func (s *Sync) IterAscend() func() Item {
+	return s.snapshot().IterAscend()
+}
+
+// Cursor returns a new cursor on a snapshot of the tree. It is not valid until
+// it is positioned with SeekFirst, SeekLast or Seek.
+func (s *Sync) Cursor() *Cursor {
+	return s.snapshot().Cursor()
+}
@@ -0,0 +1,73 @@
+//go:build go1.23
+
+package llrb
+
+import "iter"
+
+// The sequences of a Sync mirror those of seq.go. Each one traverses a snapshot
+// of the tree taken when its iteration begins, so the body of the loop may call
+// the methods of the Sync.
+
+// All returns a sequence of the elements in ascending order.
+func (s *Sync) All() iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().Ascend(i) })
+}
+
+// Backward returns a sequence of the elements in descending order.
+func (s *Sync) Backward() iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().Descend(i) })
+}
+
+// AscendRangeSeq returns a sequence of the elements greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+func (s *Sync) AscendRangeSeq(greaterOrEqual, lessThan Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().AscendRange(greaterOrEqual, lessThan, i) })
+}
+
+// AscendGreaterOrEqualSeq returns a sequence of the elements greater or equal to
+// pivot, in ascending order.
+func (s *Sync) AscendGreaterOrEqualSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().AscendGreaterOrEqual(pivot, i) })
+}
+
+// AscendGreaterThanSeq returns a sequence of the elements greater than pivot,
+// in ascending order.
+func (s *Sync) AscendGreaterThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().AscendGreaterThan(pivot, i) })
+}
+
+// AscendLessThanSeq returns a sequence of the elements less than pivot,
+// in ascending order.
+func (s *Sync) AscendLessThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().AscendLessThan(pivot, i) })
+}
+
+// AscendEqualSeq returns a sequence of the elements whose order is the same as
+// that of key, in insertion order.
+func (s *Sync) AscendEqualSeq(key Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().AscendEqual(key, i) })
+}
+
+// DescendRangeSeq returns a sequence of the elements less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+func (s *Sync) DescendRangeSeq(lessOrEqual, greaterThan Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().DescendRange(lessOrEqual, greaterThan, i) })
+}
+
+// DescendLessOrEqualSeq returns a sequence of the elements less than or equal to
+// pivot, in descending order.
+func (s *Sync) DescendLessOrEqualSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().DescendLessOrEqual(pivot, i) })
+}
+
+// DescendGreaterThanSeq returns a sequence of the elements greater than pivot,
+// in descending order.
+func (s *Sync) DescendGreaterThanSeq(pivot Item) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().DescendGreaterThan(pivot, i) })
+}
+
+// RangeSeq returns a sequence of the elements between the bounds lo and hi,
+// in the order given by dir.
+func (s *Sync) RangeSeq(lo, hi Bound, dir Direction) iter.Seq[Item] {
+	return seq(func(i ItemIterator) { s.snapshot().Range(lo, hi, dir, i) })
+}
@@ -0,0 +1,151 @@
+package llrb
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestSyncConcurrent(t *testing.T) {
+	s := NewSync()
+	const writers, n = 4, 500
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, i := range rand.Perm(n) {
+				s.ReplaceOrInsert(Int(i*writers + w))
+				if i%2 == 1 {
+					s.Delete(Int(i*writers + w))
+				}
+			}
+		}(w)
+	}
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				var prev Item
+				s.AscendRange(Int(n/2), Int(3*n/2), func(i Item) bool {
+					if prev != nil && prev.(Int) >= i.(Int) {
+						t.Errorf("out of order: %v after %v", i, prev)
+					}
+					prev = i
+					return true
+				})
+				p, k := s.Snapshot(), 0
+				p.AscendGreaterOrEqual(Inf(-1), func(Item) bool {
+					k++
+					return true
+				})
+				if k != p.Len() {
+					t.Errorf("snapshot holds %d items but visited %d", p.Len(), k)
+				}
+				if c := s.Clone(); len(collect(c.Ascend)) != c.Len() {
+					t.Errorf("clone holds %d items but visited %d", c.Len(), len(collect(c.Ascend)))
+				}
+				s.GetHeight(Int(n))
+				s.HeightStats()
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+	if s.Len() != writers*n/2 {
+		t.Errorf("expected %d items but got %d", writers*n/2, s.Len())
+	}
+	s.View(func(tree *LLRB) { checkLLRB(t, tree) })
+}
+
+func TestSyncSnapshot(t *testing.T) {
+	s := NewSync()
+	for i := 0; i < 100; i++ {
+		s.ReplaceOrInsert(Int(i))
+	}
+	p := s.Snapshot()
+	// The snapshot may be traversed while the tree is modified.
+	n := 0
+	p.AscendGreaterOrEqual(Inf(-1), func(i Item) bool {
+		s.Delete(i)
+		n++
+		return true
+	})
+	if n != 100 || s.Len() != 0 || p.Len() != 100 {
+		t.Errorf("expected 100 visits, an empty tree and a full snapshot, got %d, %d and %d", n, s.Len(), p.Len())
+	}
+	s.Update(func(tree *LLRB) {
+		tree.ReplaceOrInsert(Int(1))
+		tree.ReplaceOrInsert(Int(2))
+	})
+	if s.Len() != 2 {
+		t.Errorf("expected 2 items but got %d", s.Len())
+	}
+}
+
+func TestSyncFilterSplit(t *testing.T) {
+	s := NewSync()
+	s.EnableRank()
+	for i := 0; i < 100; i++ {
+		s.ReplaceOrInsert(Int(i))
+	}
+	if d := s.DeleteIfInRange(Int(10), Int(20), func(i Item) bool { return i.(Int)%2 == 0 }); d != 5 {
+		t.Errorf("expected 5 deleted but got %d", d)
+	}
+	if d := s.RetainInRange(Int(90), Inf(1), func(i Item) bool { return i.(Int) < 95 }); d != 5 {
+		t.Errorf("expected 5 deleted but got %d", d)
+	}
+	left, right := s.Split(Int(50))
+	if left.Len() != 45 || right.Len() != 45 || s.Len() != 0 {
+		t.Errorf("expected 45, 45 and 0 items but got %d, %d and %d", left.Len(), right.Len(), s.Len())
+	}
+}
+
+func TestSyncCursor(t *testing.T) {
+	s := NewSync()
+	for i := 0; i < 10; i++ {
+		s.ReplaceOrInsert(Int(i))
+	}
+	// Neither the cursor nor the iterator is affected by deletions.
+	c := s.Cursor()
+	next := s.IterAscend()
+	n := 0
+	for ok := c.SeekFirst(); ok; ok = c.Next() {
+		if item := next(); item != c.Item() {
+			t.Fatalf("expected %v but got %v", c.Item(), item)
+		}
+		s.Delete(c.Item())
+		n++
+	}
+	if n != 10 || next() != nil || s.Len() != 0 {
+		t.Errorf("expected 10 visits and an empty tree, got %d and %d", n, s.Len())
+	}
+}
+
+func TestSyncHeight(t *testing.T) {
+	s := NewSync()
+	for i := 0; i < 100; i++ {
+		s.ReplaceOrInsert(Int(i))
+	}
+	var expected, got [2]float64
+	s.View(func(tree *LLRB) { expected[0], expected[1] = tree.HeightStats() })
+	got[0], got[1] = s.HeightStats()
+	if got != expected || got[0] <= 0 {
+		t.Errorf("expected height stats %v but got %v", expected, got)
+	}
+	if item, depth := s.GetHeight(Int(42)); item != Int(42) || depth < 0 {
+		t.Errorf("expected to find 42, got %v at depth %d", item, depth)
+	}
+	if item, _ := s.GetHeight(Int(100)); item != nil {
+		t.Errorf("found non-existent %v", item)
+	}
+}
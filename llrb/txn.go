@@ -0,0 +1,377 @@
+package llrb
+
+import "errors"
+
+var (
+	// ErrConflict is returned by Commit when the tree was modified after the transaction began.
+	ErrConflict = errors.New("llrb: transaction conflicts with a modification of the tree")
+	// ErrTxnDone is returned, or for modifications panicked with, when a transaction
+	// is used after Commit or Rollback.
+	ErrTxnDone = errors.New("llrb: transaction has already been committed or rolled back")
+)
+
+// Txn is a transaction on an LLRB. It works on a private copy of the tree, taken
+// in O(1) time by Clone, through which it offers the queries, traversals and
+// modifications of LLRB: its queries see the modifications made by the
+// transaction, while the original tree sees none of them until Commit publishes
+// them all at once. The ordering, rank and augmentation of the tree cannot be
+// changed in a transaction.
+//
+// Transactions are optimistic. Commit fails with ErrConflict if the original tree
+// was modified after the transaction began, for instance by another transaction
+// committed first. Transactions do not synchronize goroutines; to share a tree
+// between goroutines, hold a lock from Txn to Commit, or use Sync.Update or
+// Concurrent.Update instead.
+//
+// After Commit or Rollback, the transaction may still be queried and traversed,
+// but its modifications panic with ErrTxnDone.
+type Txn struct {
+	tree *LLRB  // The working copy
+	base *LLRB  // The tree the transaction commits to
+	mods uint64 // The modification count of base when the transaction began
+	done bool
+}
+
+// Savepoint is the state of a transaction at some point, to which RollbackTo can return.
+type Savepoint struct {
+	txn  *Txn
+	tree *LLRB
+}
+
+// Txn begins a new transaction on t.
+func (t *LLRB) Txn() *Txn {
+	return &Txn{tree: t.Clone(), base: t, mods: t.mods}
+}
+
+// Commit replaces the contents of the original tree with those of the working copy
+// and ends the transaction. If the original tree was modified after the
+// transaction began, or its rank or augmentation were changed, Commit discards
+// the transaction and returns ErrConflict.
+func (x *Txn) Commit() error {
+	if x.done {
+		return ErrTxnDone
+	}
+	x.done = true
+	if x.base.mods != x.mods || x.base.ranked != x.tree.ranked || (x.base.augment == nil) != (x.tree.augment == nil) {
+		return ErrConflict
+	}
+	// The working copy may still be read, so the original tree takes a new token
+	// and copies the nodes it shares with the working copy before modifying them.
+	x.base.root, x.base.count = x.tree.root, x.tree.count
+	x.base.cow = new(cowToken)
+	x.base.mods++
+	return nil
+}
+
+// Rollback discards the modifications made in the transaction and ends it.
+func (x *Txn) Rollback() error {
+	if x.done {
+		return ErrTxnDone
+	}
+	x.done = true
+	return nil
+}
+
+// Savepoint records the current state of the transaction in O(1) time.
+func (x *Txn) Savepoint() *Savepoint {
+	return &Savepoint{txn: x, tree: x.tree.Clone()}
+}
+
+// RollbackTo discards the modifications made in the transaction since sp was
+// recorded. Savepoints recorded in the meantime remain valid, so savepoints may
+// be nested and rolled back to in any order.
+func (x *Txn) RollbackTo(sp *Savepoint) error {
+	if sp.txn != x {
+		panic("savepoint of another transaction")
+	}
+	if x.done {
+		return ErrTxnDone
+	}
+	mods := x.tree.mods
+	*x.tree = *sp.tree.Clone()
+	x.tree.mods = mods + 1
+	return nil
+}
+
+// Queries
+
+// Len returns the number of elements in the working copy.
+func (x *Txn) Len() int {
+	return x.tree.Len()
+}
+
+// Has returns true if the working copy contains an element whose order is the same as that of key.
+func (x *Txn) Has(key Item) bool {
+	return x.tree.Has(key)
+}
+
+// Get retrieves an element from the working copy whose order is the same as that of key.
+func (x *Txn) Get(key Item) Item {
+	return x.tree.Get(key)
+}
+
+// Min returns the minimum element in the working copy.
+func (x *Txn) Min() Item {
+	return x.tree.Min()
+}
+
+// Max returns the maximum element in the working copy.
+func (x *Txn) Max() Item {
+	return x.tree.Max()
+}
+
+// Floor returns the greatest element that is less than or equal to key.
+func (x *Txn) Floor(key Item) Item {
+	return x.tree.Floor(key)
+}
+
+// Ceiling returns the least element that is greater than or equal to key.
+func (x *Txn) Ceiling(key Item) Item {
+	return x.tree.Ceiling(key)
+}
+
+// Lower returns the greatest element that is strictly less than key.
+func (x *Txn) Lower(key Item) Item {
+	return x.tree.Lower(key)
+}
+
+// Higher returns the least element that is strictly greater than key.
+func (x *Txn) Higher(key Item) Item {
+	return x.tree.Higher(key)
+}
+
+// Count returns the number of items whose order is the same as that of key.
+func (x *Txn) Count(key Item) int {
+	return x.tree.Count(key)
+}
+
+// GetAll returns the items whose order is the same as that of key,
+// in insertion order.
+func (x *Txn) GetAll(key Item) []Item {
+	return x.tree.GetAll(key)
+}
+
+// Ranked returns true if the tree maintains subtree sizes.
+func (x *Txn) Ranked() bool {
+	return x.tree.Ranked()
+}
+
+// Rank returns the number of elements that are less than key. See LLRB.Rank.
+func (x *Txn) Rank(key Item) int {
+	return x.tree.Rank(key)
+}
+
+// Select returns the element at 0-based position i in ascending order.
+// See LLRB.Select.
+func (x *Txn) Select(i int) Item {
+	return x.tree.Select(i)
+}
+
+// CountRange returns the number of elements greater or equal to greaterOrEqual
+// and less than lessThan. See LLRB.CountRange.
+func (x *Txn) CountRange(greaterOrEqual, lessThan Item) int {
+	return x.tree.CountRange(greaterOrEqual, lessThan)
+}
+
+// Summary returns the summary of the whole working copy. See LLRB.Summary.
+func (x *Txn) Summary() interface{} {
+	return x.tree.Summary()
+}
+
+// Aggregate returns the summary of the elements greater or equal to greaterOrEqual
+// and less than lessThan. See LLRB.Aggregate.
+func (x *Txn) Aggregate(greaterOrEqual, lessThan Item) interface{} {
+	return x.tree.Aggregate(greaterOrEqual, lessThan)
+}
+
+// Cursor returns a new cursor on the working copy. See LLRB.Cursor.
+func (x *Txn) Cursor() *Cursor {
+	return x.tree.Cursor()
+}
+
+// Traversals
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (x *Txn) Ascend(iterator ItemIterator) {
+	x.tree.Ascend(iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (x *Txn) Descend(iterator ItemIterator) {
+	x.tree.Descend(iterator)
+}
+
+// AscendRange will call iterator once for each element greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (x *Txn) AscendRange(greaterOrEqual, lessThan Item, iterator ItemIterator) {
+	x.tree.AscendRange(greaterOrEqual, lessThan, iterator)
+}
+
+// AscendGreaterOrEqual will call iterator once for each element greater or equal to
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (x *Txn) AscendGreaterOrEqual(pivot Item, iterator ItemIterator) {
+	x.tree.AscendGreaterOrEqual(pivot, iterator)
+}
+
+// AscendGreaterThan will call iterator once for each element greater than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (x *Txn) AscendGreaterThan(pivot Item, iterator ItemIterator) {
+	x.tree.AscendGreaterThan(pivot, iterator)
+}
+
+// AscendLessThan will call iterator once for each element lower than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (x *Txn) AscendLessThan(pivot Item, iterator ItemIterator) {
+	x.tree.AscendLessThan(pivot, iterator)
+}
+
+// AscendEqual will call iterator once for each item whose order is the same as that
+// of key, in insertion order. It will stop whenever the iterator returns false.
+func (x *Txn) AscendEqual(key Item, iterator ItemIterator) {
+	x.tree.AscendEqual(key, iterator)
+}
+
+// DescendRange will call iterator once for each element less or equal to
+// lessOrEqual and greater than greaterThan, in descending order.
+// It will stop whenever the iterator returns false.
+func (x *Txn) DescendRange(lessOrEqual, greaterThan Item, iterator ItemIterator) {
+	x.tree.DescendRange(lessOrEqual, greaterThan, iterator)
+}
+
+// DescendLessOrEqual will call iterator once for each element less than or equal to
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (x *Txn) DescendLessOrEqual(pivot Item, iterator ItemIterator) {
+	x.tree.DescendLessOrEqual(pivot, iterator)
+}
+
+// DescendGreaterThan will call iterator once for each element greater than
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (x *Txn) DescendGreaterThan(pivot Item, iterator ItemIterator) {
+	x.tree.DescendGreaterThan(pivot, iterator)
+}
+
+// Range will call iterator once for each element between the bounds lo and hi,
+// in the order given by dir. It will stop whenever the iterator returns false.
+func (x *Txn) Range(lo, hi Bound, dir Direction, iterator ItemIterator) {
+	x.tree.Range(lo, hi, dir, iterator)
+}
+
+// Modifications, which panic with ErrTxnDone after Commit or Rollback
+
+// ReplaceOrInsert inserts item into the working copy. If an existing
+// element has the same order, it is removed and returned.
+func (x *Txn) ReplaceOrInsert(item Item) Item {
+	x.mustBeActive()
+	return x.tree.ReplaceOrInsert(item)
+}
+
+// InsertNoReplace inserts item into the working copy. If an existing
+// element has the same order, both elements remain.
+func (x *Txn) InsertNoReplace(item Item) {
+	x.mustBeActive()
+	x.tree.InsertNoReplace(item)
+}
+
+// ReplaceOrInsertBulk calls ReplaceOrInsert for each of items.
+func (x *Txn) ReplaceOrInsertBulk(items ...Item) {
+	x.mustBeActive()
+	x.tree.ReplaceOrInsertBulk(items...)
+}
+
+// InsertNoReplaceBulk calls InsertNoReplace for each of items.
+func (x *Txn) InsertNoReplaceBulk(items ...Item) {
+	x.mustBeActive()
+	x.tree.InsertNoReplaceBulk(items...)
+}
+
+// Delete deletes an item whose key equals key.
+// The deleted item is returned, otherwise nil is returned.
+func (x *Txn) Delete(key Item) Item {
+	x.mustBeActive()
+	return x.tree.Delete(key)
+}
+
+// DeleteMin deletes the minimum element and returns it, or nil if there is none.
+func (x *Txn) DeleteMin() Item {
+	x.mustBeActive()
+	return x.tree.DeleteMin()
+}
+
+// DeleteMax deletes the maximum element and returns it, or nil if there is none.
+func (x *Txn) DeleteMax() Item {
+	x.mustBeActive()
+	return x.tree.DeleteMax()
+}
+
+// DeleteAll deletes all items whose order is the same as that of key,
+// and returns how many were deleted.
+func (x *Txn) DeleteAll(key Item) int {
+	x.mustBeActive()
+	return x.tree.DeleteAll(key)
+}
+
+// DeleteExact deletes the first item, in insertion order, whose order is the same
+// as that of item and for which eq(item, candidate) is true. See LLRB.DeleteExact.
+func (x *Txn) DeleteExact(item Item, eq func(a, b Item) bool) Item {
+	x.mustBeActive()
+	return x.tree.DeleteExact(item, eq)
+}
+
+// DeleteRange deletes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns how many were deleted.
+func (x *Txn) DeleteRange(greaterOrEqual, lessThan Item) int {
+	x.mustBeActive()
+	return x.tree.DeleteRange(greaterOrEqual, lessThan)
+}
+
+// ExtractRange removes the elements greater or equal to greaterOrEqual and less than
+// lessThan, and returns them as a new tree. See LLRB.ExtractRange.
+func (x *Txn) ExtractRange(greaterOrEqual, lessThan Item) *LLRB {
+	x.mustBeActive()
+	return x.tree.ExtractRange(greaterOrEqual, lessThan)
+}
+
+// DeleteIf deletes every element for which pred returns true, and returns how many
+// were deleted. See LLRB.DeleteIfInRange.
+func (x *Txn) DeleteIf(pred func(Item) bool) int {
+	x.mustBeActive()
+	return x.tree.DeleteIf(pred)
+}
+
+// Retain deletes every element for which pred returns false, and returns how many
+// were deleted. See LLRB.DeleteIfInRange.
+func (x *Txn) Retain(pred func(Item) bool) int {
+	x.mustBeActive()
+	return x.tree.Retain(pred)
+}
+
+// DeleteIfInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns true, and returns how many were deleted.
+// See LLRB.DeleteIfInRange.
+func (x *Txn) DeleteIfInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	x.mustBeActive()
+	return x.tree.DeleteIfInRange(greaterOrEqual, lessThan, pred)
+}
+
+// RetainInRange deletes every element greater or equal to greaterOrEqual and less
+// than lessThan for which pred returns false, and returns how many were deleted.
+// See LLRB.DeleteIfInRange.
+func (x *Txn) RetainInRange(greaterOrEqual, lessThan Item, pred func(Item) bool) int {
+	x.mustBeActive()
+	return x.tree.RetainInRange(greaterOrEqual, lessThan, pred)
+}
+
+// LoadSorted replaces the contents of the working copy with items. See LLRB.LoadSorted.
+func (x *Txn) LoadSorted(items []Item, allowDuplicates bool) error {
+	x.mustBeActive()
+	return x.tree.LoadSorted(items, allowDuplicates)
+}
+
+func (x *Txn) mustBeActive() {
+	if x.done {
+		panic(ErrTxnDone)
+	}
+}
@@ -0,0 +1,174 @@
+package llrb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTxnCommitRollback(t *testing.T) {
+	tree := intTree(true, 1, 2, 3, 4, 5)
+	x := tree.Txn()
+	x.ReplaceOrInsert(Int(6))
+	x.Delete(Int(1))
+	x.DeleteMax()
+	x.DeleteRange(Int(3), Int(4))
+	if !x.Has(Int(2)) || x.Has(Int(1)) || x.Has(Int(6)) || x.Len() != 3 || x.Rank(Int(5)) != 2 {
+		t.Errorf("expected the transaction to see its own writes")
+	}
+	if tree.Len() != 5 || !tree.Has(Int(1)) || tree.Has(Int(6)) {
+		t.Errorf("expected the tree to be unaffected before commit")
+	}
+	c := tree.Cursor()
+	c.SeekFirst()
+	if err := x.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	checkLLRB(t, tree)
+	if items := collect(tree.Ascend); !reflect.DeepEqual(items, []Item{Int(2), Int(4), Int(5)}) {
+		t.Errorf("expected the committed items, got %v", items)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expecting a panic from a cursor opened before commit")
+			}
+		}()
+		c.Next()
+	}()
+	if err := x.Commit(); err != ErrTxnDone {
+		t.Errorf("expected ErrTxnDone, got %v", err)
+	}
+	// A finished transaction may be read but not modified.
+	if x.Len() != 3 || !x.Has(Int(2)) {
+		t.Errorf("expected a finished transaction to keep its contents")
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrTxnDone {
+				t.Errorf("expected a panic with ErrTxnDone, got %v", r)
+			}
+		}()
+		x.ReplaceOrInsert(Int(7))
+	}()
+	if tree.Has(Int(7)) {
+		t.Errorf("expected a finished transaction to be detached")
+	}
+
+	x = tree.Txn()
+	x.DeleteMin()
+	if err := x.Rollback(); err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if tree.Len() != 3 || !tree.Has(Int(2)) {
+		t.Errorf("expected a rolled back transaction to have no effect")
+	}
+	if err := x.Rollback(); err != ErrTxnDone {
+		t.Errorf("expected ErrTxnDone, got %v", err)
+	}
+}
+
+func TestTxnConflict(t *testing.T) {
+	tree := intTree(false, 1, 2, 3)
+	x, y := tree.Txn(), tree.Txn()
+	x.ReplaceOrInsert(Int(4))
+	y.ReplaceOrInsert(Int(5))
+	if err := x.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	if err := y.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if !tree.Has(Int(4)) || tree.Has(Int(5)) {
+		t.Errorf("expected only the first transaction to be committed")
+	}
+	z := tree.Txn()
+	z.ReplaceOrInsert(Int(6))
+	tree.Delete(Int(1))
+	if err := z.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	checkLLRB(t, tree)
+	if tree.Len() != 3 || tree.Has(Int(6)) {
+		t.Errorf("expected the tree to keep its own modification only")
+	}
+}
+
+func TestTxnSavepoints(t *testing.T) {
+	tree := intTree(false)
+	x := tree.Txn()
+	x.ReplaceOrInsert(Int(1))
+	sp1 := x.Savepoint()
+	x.ReplaceOrInsert(Int(2))
+	sp2 := x.Savepoint()
+	x.ReplaceOrInsert(Int(3))
+	if err := x.RollbackTo(sp2); err != nil {
+		t.Fatalf("rollback to savepoint failed: %v", err)
+	}
+	if items := collect(x.Ascend); !reflect.DeepEqual(items, []Item{Int(1), Int(2)}) {
+		t.Errorf("expected [1 2], got %v", items)
+	}
+	x.ReplaceOrInsert(Int(4))
+	x.RollbackTo(sp1)
+	if items := collect(x.Ascend); !reflect.DeepEqual(items, []Item{Int(1)}) {
+		t.Errorf("expected [1], got %v", items)
+	}
+	// A savepoint may be rolled back to more than once, in any order.
+	x.RollbackTo(sp2)
+	x.RollbackTo(sp2)
+	x.ReplaceOrInsert(Int(5))
+	if err := x.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	checkLLRB(t, tree)
+	if items := collect(tree.Ascend); !reflect.DeepEqual(items, []Item{Int(1), Int(2), Int(5)}) {
+		t.Errorf("expected [1 2 5], got %v", items)
+	}
+	if err := x.RollbackTo(sp1); err != ErrTxnDone {
+		t.Errorf("expected ErrTxnDone, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic")
+		}
+	}()
+	tree.Txn().RollbackTo(sp1)
+}
+
+func TestTxnCommitKeepsSettings(t *testing.T) {
+	tree := intTree(false, 1, 2, 3)
+	x := tree.Txn()
+	x.ReplaceOrInsert(Int(4))
+	tree.EnableRank()
+	if err := x.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict after EnableRank, got %v", err)
+	}
+	x = tree.Txn()
+	x.ReplaceOrInsert(Int(4))
+	tree.SetAugment(sumAugment)
+	if err := x.Commit(); err != ErrConflict {
+		t.Errorf("expected ErrConflict after SetAugment, got %v", err)
+	}
+	x = tree.Txn()
+	x.ReplaceOrInsert(Int(5))
+	c := tree.Cursor()
+	c.SeekFirst()
+	if err := x.Commit(); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	checkLLRB(t, tree)
+	checkSummaries(t, tree, tree.root)
+	if !tree.Ranked() || tree.Summary() != 11 || tree.Select(3) != Int(5) {
+		t.Errorf("expected the tree to stay ranked and augmented")
+	}
+	// The finished transaction is unaffected by later modifications of the tree.
+	tree.Delete(Int(1))
+	if !x.Has(Int(1)) || x.Len() != 4 {
+		t.Errorf("expected the finished transaction to keep its contents")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expecting a panic from a cursor opened before commit")
+		}
+	}()
+	c.Next()
+}
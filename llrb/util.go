@@ -4,14 +4,30 @@
 
 package llrb
 
+import "strings"
+
 type Int int
 
 func (x Int) Less(than Item) bool {
 	return x < than.(Int)
 }
 
+func (x Int) Compare(than Item) int {
+	switch y := than.(Int); {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
 type String string
 
 func (x String) Less(than Item) bool {
 	return x < than.(String)
 }
+
+func (x String) Compare(than Item) int {
+	return strings.Compare(string(x), string(than.(String)))
+}
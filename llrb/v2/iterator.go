@@ -0,0 +1,133 @@
+package llrb
+
+// Iterator is called once for each visited element. Returning false stops the traversal.
+type Iterator[T any] func(item T) bool
+
+// Ascend will call iterator once for each element in ascending order.
+// It will stop whenever the iterator returns false.
+func (t *Tree[T]) Ascend(iterator Iterator[T]) {
+	ascend(t.root, iterator)
+}
+
+func ascend[T any](h *node[T], iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if !ascend(h.left, iterator) {
+		return false
+	}
+	if !iterator(h.item) {
+		return false
+	}
+	return ascend(h.right, iterator)
+}
+
+// Descend will call iterator once for each element in descending order.
+// It will stop whenever the iterator returns false.
+func (t *Tree[T]) Descend(iterator Iterator[T]) {
+	descend(t.root, iterator)
+}
+
+func descend[T any](h *node[T], iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if !descend(h.right, iterator) {
+		return false
+	}
+	if !iterator(h.item) {
+		return false
+	}
+	return descend(h.left, iterator)
+}
+
+// AscendRange will call iterator once for each element greater or equal to
+// greaterOrEqual and less than lessThan, in ascending order.
+// It will stop whenever the iterator returns false.
+func (t *Tree[T]) AscendRange(greaterOrEqual, lessThan T, iterator Iterator[T]) {
+	t.ascendRange(t.root, greaterOrEqual, lessThan, iterator)
+}
+
+func (t *Tree[T]) ascendRange(h *node[T], inf, sup T, iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if t.cmp(h.item, sup) >= 0 {
+		return t.ascendRange(h.left, inf, sup, iterator)
+	}
+	if t.cmp(h.item, inf) < 0 {
+		return t.ascendRange(h.right, inf, sup, iterator)
+	}
+
+	if !t.ascendRange(h.left, inf, sup, iterator) {
+		return false
+	}
+	if !iterator(h.item) {
+		return false
+	}
+	return t.ascendRange(h.right, inf, sup, iterator)
+}
+
+// AscendGreaterOrEqual will call iterator once for each element greater or equal to
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (t *Tree[T]) AscendGreaterOrEqual(pivot T, iterator Iterator[T]) {
+	t.ascendGreaterOrEqual(t.root, pivot, iterator)
+}
+
+func (t *Tree[T]) ascendGreaterOrEqual(h *node[T], pivot T, iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if t.cmp(h.item, pivot) >= 0 {
+		if !t.ascendGreaterOrEqual(h.left, pivot, iterator) {
+			return false
+		}
+		if !iterator(h.item) {
+			return false
+		}
+	}
+	return t.ascendGreaterOrEqual(h.right, pivot, iterator)
+}
+
+// AscendLessThan will call iterator once for each element lower than
+// pivot in ascending order. It will stop whenever the iterator returns false.
+func (t *Tree[T]) AscendLessThan(pivot T, iterator Iterator[T]) {
+	t.ascendLessThan(t.root, pivot, iterator)
+}
+
+func (t *Tree[T]) ascendLessThan(h *node[T], pivot T, iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if !t.ascendLessThan(h.left, pivot, iterator) {
+		return false
+	}
+	if t.cmp(h.item, pivot) < 0 {
+		if !iterator(h.item) {
+			return false
+		}
+		return t.ascendLessThan(h.right, pivot, iterator)
+	}
+	return true
+}
+
+// DescendLessOrEqual will call iterator once for each element less than or equal to
+// pivot in descending order. It will stop whenever the iterator returns false.
+func (t *Tree[T]) DescendLessOrEqual(pivot T, iterator Iterator[T]) {
+	t.descendLessOrEqual(t.root, pivot, iterator)
+}
+
+func (t *Tree[T]) descendLessOrEqual(h *node[T], pivot T, iterator Iterator[T]) bool {
+	if h == nil {
+		return true
+	}
+	if t.cmp(h.item, pivot) <= 0 {
+		if !t.descendLessOrEqual(h.right, pivot, iterator) {
+			return false
+		}
+		if !iterator(h.item) {
+			return false
+		}
+	}
+	return t.descendLessOrEqual(h.left, pivot, iterator)
+}
@@ -0,0 +1,47 @@
+// Copyright 2010 Petar Maymounkov. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+// GetHeight returns an item in the tree with key @key, and it's height in the tree
+func (t *Tree[T]) GetHeight(key T) (result T, depth int, ok bool) {
+	return t.getHeight(t.root, key)
+}
+
+func (t *Tree[T]) getHeight(h *node[T], item T) (T, int, bool) {
+	if h == nil {
+		var zero T
+		return zero, 0, false
+	}
+	switch c := t.cmp(item, h.item); {
+	case c < 0:
+		result, depth, ok := t.getHeight(h.left, item)
+		return result, depth + 1, ok
+	case c > 0:
+		result, depth, ok := t.getHeight(h.right, item)
+		return result, depth + 1, ok
+	}
+	return h.item, 0, true
+}
+
+// HeightStats returns the average and standard deviation of the height
+// of elements in the tree
+func (t *Tree[T]) HeightStats() (avg, stddev float64) {
+	av := &avgVar{}
+	heightStats(t.root, 0, av)
+	return av.GetAvg(), av.GetStdDev()
+}
+
+func heightStats[T any](h *node[T], d int, av *avgVar) {
+	if h == nil {
+		return
+	}
+	av.Add(float64(d))
+	if h.left != nil {
+		heightStats(h.left, d+1, av)
+	}
+	if h.right != nil {
+		heightStats(h.right, d+1, av)
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright 2010 Petar Maymounkov. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// A type-parameterized Left-Leaning Red-Black (LLRB) implementation of 2-3 balanced
+// binary search trees. It mirrors the API of github.com/petar/GoLLRB/llrb, except that
+// items are stored inline as values of type T and ordered by a comparison function
+// supplied at construction time, rather than through the Item interface.
+//
+//	http://www.cs.princeton.edu/~rs/talks/LLRB/08Penn.pdf
+//	http://www.cs.princeton.edu/~rs/talks/LLRB/LLRB.pdf
+//	http://www.cs.princeton.edu/~rs/talks/LLRB/Java/RedBlackBST.java
+package llrb
+
+// Tree is a Left-Leaning Red-Black (LLRB) implementation of 2-3 trees
+type Tree[T any] struct {
+	cmp   func(a, b T) int
+	count int
+	root  *node[T]
+}
+
+type node[T any] struct {
+	item        T
+	left, right *node[T] // Pointers to left and right child nodes
+	black       bool     // If set, the color of the link (incoming from the parent) is black
+	// In the LLRB, new nodes are always red, hence the zero-value for node
+}
+
+// New allocates a new tree ordered by less, which must report whether a sorts before b.
+func New[T any](less func(a, b T) bool) *Tree[T] {
+	if less == nil {
+		panic("nil less function")
+	}
+	return NewCmp(func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+}
+
+// NewCmp allocates a new tree ordered by cmp, which must return a negative number
+// when a sorts before b, a positive number when a sorts after b, and zero otherwise.
+func NewCmp[T any](cmp func(a, b T) int) *Tree[T] {
+	if cmp == nil {
+		panic("nil cmp function")
+	}
+	return &Tree[T]{cmp: cmp}
+}
+
+// Len returns the number of nodes in the tree.
+func (t *Tree[T]) Len() int { return t.count }
+
+// Has returns true if the tree contains an element whose order is the same as that of key.
+func (t *Tree[T]) Has(key T) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// Get retrieves an element from the tree whose order is the same as that of key.
+func (t *Tree[T]) Get(key T) (T, bool) {
+	h := t.root
+	for h != nil {
+		switch c := t.cmp(key, h.item); {
+		case c < 0:
+			h = h.left
+		case c > 0:
+			h = h.right
+		default:
+			return h.item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Min returns the minimum element in the tree.
+func (t *Tree[T]) Min() (T, bool) {
+	h := t.root
+	if h == nil {
+		var zero T
+		return zero, false
+	}
+	for h.left != nil {
+		h = h.left
+	}
+	return h.item, true
+}
+
+// Max returns the maximum element in the tree.
+func (t *Tree[T]) Max() (T, bool) {
+	h := t.root
+	if h == nil {
+		var zero T
+		return zero, false
+	}
+	for h.right != nil {
+		h = h.right
+	}
+	return h.item, true
+}
+
+func (t *Tree[T]) ReplaceOrInsertBulk(items ...T) {
+	for _, i := range items {
+		t.ReplaceOrInsert(i)
+	}
+}
+
+func (t *Tree[T]) InsertNoReplaceBulk(items ...T) {
+	for _, i := range items {
+		t.InsertNoReplace(i)
+	}
+}
+
+// ReplaceOrInsert inserts item into the tree. If an existing
+// element has the same order, it is removed from the tree and returned.
+func (t *Tree[T]) ReplaceOrInsert(item T) (replaced T, ok bool) {
+	t.root, replaced, ok = t.replaceOrInsert(t.root, item)
+	t.root.black = true
+	if !ok {
+		t.count++
+	}
+	return replaced, ok
+}
+
+func (t *Tree[T]) replaceOrInsert(h *node[T], item T) (*node[T], T, bool) {
+	if h == nil {
+		var zero T
+		return &node[T]{item: item}, zero, false
+	}
+
+	var replaced T
+	var ok bool
+	switch c := t.cmp(item, h.item); {
+	case c < 0:
+		h.left, replaced, ok = t.replaceOrInsert(h.left, item)
+	case c > 0:
+		h.right, replaced, ok = t.replaceOrInsert(h.right, item)
+	default:
+		replaced, h.item, ok = h.item, item, true
+	}
+
+	return walkUpRot23(h), replaced, ok
+}
+
+// InsertNoReplace inserts item into the tree. If an existing
+// element has the same order, both elements remain in the tree.
+func (t *Tree[T]) InsertNoReplace(item T) {
+	t.root = t.insertNoReplace(t.root, item)
+	t.root.black = true
+	t.count++
+}
+
+func (t *Tree[T]) insertNoReplace(h *node[T], item T) *node[T] {
+	if h == nil {
+		return &node[T]{item: item}
+	}
+
+	if t.cmp(item, h.item) < 0 {
+		h.left = t.insertNoReplace(h.left, item)
+	} else {
+		h.right = t.insertNoReplace(h.right, item)
+	}
+
+	return walkUpRot23(h)
+}
+
+// Rotation driver routine for 2-3 algorithm
+
+func walkUpRot23[T any](h *node[T]) *node[T] {
+	if isRed(h.right) && !isRed(h.left) {
+		h = rotateLeft(h)
+	}
+
+	if isRed(h.left) && isRed(h.left.left) {
+		h = rotateRight(h)
+	}
+
+	if isRed(h.left) && isRed(h.right) {
+		flip(h)
+	}
+
+	return h
+}
+
+// DeleteMin deletes the minimum element in the tree and returns the
+// deleted item. The boolean result is false if the tree was empty.
+func (t *Tree[T]) DeleteMin() (deleted T, ok bool) {
+	t.root, deleted, ok = deleteMin(t.root)
+	if t.root != nil {
+		t.root.black = true
+	}
+	if ok {
+		t.count--
+	}
+	return deleted, ok
+}
+
+// deleteMin code for LLRB 2-3 trees
+func deleteMin[T any](h *node[T]) (*node[T], T, bool) {
+	if h == nil {
+		var zero T
+		return nil, zero, false
+	}
+	if h.left == nil {
+		return nil, h.item, true
+	}
+
+	if !isRed(h.left) && !isRed(h.left.left) {
+		h = moveRedLeft(h)
+	}
+
+	var deleted T
+	var ok bool
+	h.left, deleted, ok = deleteMin(h.left)
+
+	return fixUp(h), deleted, ok
+}
+
+// DeleteMax deletes the maximum element in the tree and returns the
+// deleted item. The boolean result is false if the tree was empty.
+func (t *Tree[T]) DeleteMax() (deleted T, ok bool) {
+	t.root, deleted, ok = deleteMax(t.root)
+	if t.root != nil {
+		t.root.black = true
+	}
+	if ok {
+		t.count--
+	}
+	return deleted, ok
+}
+
+func deleteMax[T any](h *node[T]) (*node[T], T, bool) {
+	if h == nil {
+		var zero T
+		return nil, zero, false
+	}
+	if isRed(h.left) {
+		h = rotateRight(h)
+	}
+	if h.right == nil {
+		return nil, h.item, true
+	}
+	if !isRed(h.right) && !isRed(h.right.left) {
+		h = moveRedRight(h)
+	}
+	var deleted T
+	var ok bool
+	h.right, deleted, ok = deleteMax(h.right)
+
+	return fixUp(h), deleted, ok
+}
+
+// Delete deletes an item from the tree whose key equals key.
+// The deleted item is returned. The boolean result is false if no such item was found.
+func (t *Tree[T]) Delete(key T) (deleted T, ok bool) {
+	t.root, deleted, ok = t.delete(t.root, key)
+	if t.root != nil {
+		t.root.black = true
+	}
+	if ok {
+		t.count--
+	}
+	return deleted, ok
+}
+
+func (t *Tree[T]) delete(h *node[T], item T) (*node[T], T, bool) {
+	var deleted T
+	var ok bool
+	if h == nil {
+		return nil, deleted, false
+	}
+	if t.cmp(item, h.item) < 0 {
+		if h.left == nil { // item not present. Nothing to delete
+			return h, deleted, false
+		}
+		if !isRed(h.left) && !isRed(h.left.left) {
+			h = moveRedLeft(h)
+		}
+		h.left, deleted, ok = t.delete(h.left, item)
+	} else {
+		if isRed(h.left) {
+			h = rotateRight(h)
+		}
+		// If @item equals @h.item and no right children at @h
+		if t.cmp(h.item, item) >= 0 && h.right == nil {
+			return nil, h.item, true
+		}
+		descend := false
+		if h.right != nil && !isRed(h.right) && !isRed(h.right.left) {
+			if x := moveRedRight(h); x != h {
+				// The rotation moved the old @h, which may itself equal @item,
+				// into the right subtree. Continue the search there, since
+				// only that subtree is prepared for a deletion.
+				h, descend = x, true
+			}
+		}
+		// If @item equals @h.item, and (from above) 'h.right != nil'
+		if !descend && t.cmp(h.item, item) >= 0 {
+			var subDeleted T
+			h.right, subDeleted, ok = deleteMin(h.right)
+			if !ok {
+				panic("logic")
+			}
+			deleted, h.item = h.item, subDeleted
+		} else { // Else, @item is bigger than @h.item
+			h.right, deleted, ok = t.delete(h.right, item)
+		}
+	}
+
+	return fixUp(h), deleted, ok
+}
+
+// Internal node manipulation routines
+
+func isRed[T any](h *node[T]) bool {
+	if h == nil {
+		return false
+	}
+	return !h.black
+}
+
+func rotateLeft[T any](h *node[T]) *node[T] {
+	x := h.right
+	if x.black {
+		panic("rotating a black link")
+	}
+	h.right = x.left
+	x.left = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+func rotateRight[T any](h *node[T]) *node[T] {
+	x := h.left
+	if x.black {
+		panic("rotating a black link")
+	}
+	h.left = x.right
+	x.right = h
+	x.black = h.black
+	h.black = false
+	return x
+}
+
+// REQUIRE: Left and Right children must be present
+func flip[T any](h *node[T]) {
+	h.black = !h.black
+	h.left.black = !h.left.black
+	h.right.black = !h.right.black
+}
+
+// REQUIRE: Left and Right children must be present
+func moveRedLeft[T any](h *node[T]) *node[T] {
+	flip(h)
+	if isRed(h.right.left) {
+		h.right = rotateRight(h.right)
+		h = rotateLeft(h)
+		flip(h)
+	}
+	return h
+}
+
+// REQUIRE: Left and Right children must be present
+func moveRedRight[T any](h *node[T]) *node[T] {
+	flip(h)
+	if isRed(h.left.left) {
+		h = rotateRight(h)
+		flip(h)
+	}
+	return h
+}
+
+func fixUp[T any](h *node[T]) *node[T] {
+	if isRed(h.right) {
+		h = rotateLeft(h)
+	}
+
+	if isRed(h.left) && isRed(h.left.left) {
+		h = rotateRight(h)
+	}
+
+	if isRed(h.left) && isRed(h.right) {
+		flip(h)
+	}
+
+	return h
+}
@@ -0,0 +1,247 @@
+// Copyright 2010 Petar Maymounkov. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package llrb
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func lessInt(a, b int) bool { return a < b }
+
+func cmpInt(a, b int) int { return a - b }
+
+func TestCases(t *testing.T) {
+	tree := New(lessInt)
+	tree.ReplaceOrInsert(1)
+	tree.ReplaceOrInsert(1)
+	if tree.Len() != 1 {
+		t.Errorf("expecting len 1")
+	}
+	if !tree.Has(1) {
+		t.Errorf("expecting to find key=1")
+	}
+
+	tree.Delete(1)
+	if tree.Len() != 0 {
+		t.Errorf("expecting len 0")
+	}
+	if tree.Has(1) {
+		t.Errorf("not expecting to find key=1")
+	}
+
+	if _, ok := tree.Delete(1); ok {
+		t.Errorf("deleted non-existent item")
+	}
+	if tree.Len() != 0 {
+		t.Errorf("expecting len 0")
+	}
+}
+
+func TestRandomInsertOrder(t *testing.T) {
+	tree := NewCmp(cmpInt)
+	n := 1000
+	perm := rand.Perm(n)
+	for i := 0; i < n; i++ {
+		tree.ReplaceOrInsert(perm[i])
+	}
+	j := 0
+	tree.Ascend(func(item int) bool {
+		if item != j {
+			t.Fatalf("bad order")
+		}
+		j++
+		return true
+	})
+	if j != n {
+		t.Errorf("expecting %d items, got %d", n, j)
+	}
+	if min, _ := tree.Min(); min != 0 {
+		t.Errorf("expecting min 0, got %d", min)
+	}
+	if max, _ := tree.Max(); max != n-1 {
+		t.Errorf("expecting max %d, got %d", n-1, max)
+	}
+}
+
+func TestRandomReplace(t *testing.T) {
+	type pair struct{ k, v int }
+	tree := New(func(a, b pair) bool { return a.k < b.k })
+	n := 100
+	perm := rand.Perm(n)
+	for i := 0; i < n; i++ {
+		tree.ReplaceOrInsert(pair{perm[i], 0})
+	}
+	perm = rand.Perm(n)
+	for i := 0; i < n; i++ {
+		if replaced, ok := tree.ReplaceOrInsert(pair{perm[i], 1}); !ok || replaced != (pair{perm[i], 0}) {
+			t.Errorf("error replacing")
+		}
+	}
+	if got, _ := tree.Get(pair{k: 7}); got.v != 1 {
+		t.Errorf("expecting replaced value")
+	}
+}
+
+func TestRandomInsertDelete(t *testing.T) {
+	tree := New(lessInt)
+	n := 1000
+	perm := rand.Perm(n)
+	for i := 0; i < n; i++ {
+		tree.ReplaceOrInsert(perm[i])
+	}
+	if _, ok := tree.Delete(n + 1); ok {
+		t.Errorf("deleted non-existent item")
+	}
+	perm = rand.Perm(n)
+	for i := 0; i < n; i++ {
+		if u, ok := tree.Delete(perm[i]); !ok || u != perm[i] {
+			t.Errorf("delete failed")
+		}
+	}
+	if tree.Len() != 0 {
+		t.Errorf("expecting len 0, got %d", tree.Len())
+	}
+}
+
+func TestDeleteMinMax(t *testing.T) {
+	tree := New(lessInt)
+	n := 100
+	for _, i := range rand.Perm(n) {
+		tree.InsertNoReplace(i)
+	}
+	for i := 0; i < n/2; i++ {
+		if u, ok := tree.DeleteMin(); !ok || u != i {
+			t.Fatalf("expecting min %d, got %d", i, u)
+		}
+		if u, ok := tree.DeleteMax(); !ok || u != n-1-i {
+			t.Fatalf("expecting max %d, got %d", n-1-i, u)
+		}
+	}
+	if _, ok := tree.DeleteMin(); ok {
+		t.Errorf("deleted from empty tree")
+	}
+}
+
+func TestInsertNoReplace(t *testing.T) {
+	tree := New(lessInt)
+	n := 1000
+	for q := 0; q < 2; q++ {
+		perm := rand.Perm(n)
+		for i := 0; i < n; i++ {
+			tree.InsertNoReplace(perm[i])
+		}
+	}
+	j := 0
+	tree.Ascend(func(item int) bool {
+		if item != j/2 {
+			t.Fatalf("bad order")
+		}
+		j++
+		return true
+	})
+}
+
+func TestDeleteDuplicates(t *testing.T) {
+	for trial := 0; trial < 200; trial++ {
+		tree := New(lessInt)
+		n := 4 + rand.Intn(30)
+		copies := make([]int, n)
+		for c := 0; c < 3; c++ {
+			for _, i := range rand.Perm(n) {
+				tree.InsertNoReplace(i)
+				copies[i]++
+			}
+		}
+		for tree.Len() > 0 {
+			k := rand.Intn(n)
+			before := tree.Len()
+			d, ok := tree.Delete(k)
+			if copies[k] == 0 {
+				if ok || tree.Len() != before {
+					t.Fatalf("deleted non-existent %d", k)
+				}
+				continue
+			}
+			if !ok || d != k {
+				t.Fatalf("expecting to delete %d, got %d, %v", k, d, ok)
+			}
+			if tree.Len() != before-1 {
+				t.Fatalf("expecting len %d after deleting %d, got %d", before-1, k, tree.Len())
+			}
+			copies[k]--
+			if v, ok := tree.Get(k); ok != (copies[k] > 0) || (ok && v != k) {
+				t.Fatalf("expecting %d copies of %d to remain, got %d, %v", copies[k], k, v, ok)
+			}
+			if avg, _ := tree.HeightStats(); tree.Len() > 0 && avg > 2*math.Log2(float64(3*n)) {
+				t.Fatalf("unbalanced tree")
+			}
+		}
+	}
+}
+
+func TestIterators(t *testing.T) {
+	tree := New(lessInt)
+	tree.InsertNoReplaceBulk(4, 6, 1, 3)
+	collect := func(walk func(Iterator[int])) []int {
+		var ary []int
+		walk(func(i int) bool {
+			ary = append(ary, i)
+			return true
+		})
+		return ary
+	}
+	cases := []struct {
+		walk     func(Iterator[int])
+		expected []int
+	}{
+		{tree.Ascend, []int{1, 3, 4, 6}},
+		{tree.Descend, []int{6, 4, 3, 1}},
+		{func(i Iterator[int]) { tree.AscendRange(2, 6, i) }, []int{3, 4}},
+		{func(i Iterator[int]) { tree.AscendGreaterOrEqual(3, i) }, []int{3, 4, 6}},
+		{func(i Iterator[int]) { tree.AscendLessThan(4, i) }, []int{1, 3}},
+		{func(i Iterator[int]) { tree.DescendLessOrEqual(5, i) }, []int{4, 3, 1}},
+	}
+	for k, c := range cases {
+		if got := collect(c.walk); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d: expected %v but got %v", k, c.expected, got)
+		}
+	}
+}
+
+func TestRandomInsertStats(t *testing.T) {
+	tree := New(lessInt)
+	n := 100000
+	perm := rand.Perm(n)
+	for i := 0; i < n; i++ {
+		tree.ReplaceOrInsert(perm[i])
+	}
+	avg, _ := tree.HeightStats()
+	expAvg := math.Log2(float64(n)) - 1.5
+	if math.Abs(avg-expAvg) >= 2.0 {
+		t.Errorf("too much deviation from expected average height")
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	tree := New(lessInt)
+	for i := 0; i < b.N; i++ {
+		tree.ReplaceOrInsert(b.N - i)
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	b.StopTimer()
+	tree := New(lessInt)
+	for i := 0; i < b.N; i++ {
+		tree.ReplaceOrInsert(b.N - i)
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Delete(i)
+	}
+}